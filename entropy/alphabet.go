@@ -0,0 +1,116 @@
+package entropy
+
+import "math/rand"
+
+// Alphabets for use with [RandStrFrom].
+const (
+	// AlphabetHex is lowercase hexadecimal.
+	AlphabetHex = "0123456789abcdef"
+	// AlphabetCrockford is Crockford's base32, which leaves out I, L, O and U to avoid misreadings.
+	AlphabetCrockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// AlphabetBase58 is the Bitcoin base58 alphabet, which leaves out 0, O, I and l.
+	AlphabetBase58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// AlphabetBase62 is digits and mixed case letters.
+	AlphabetBase62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// AlphabetURLSafe is the unpadded base64url alphabet, safe in URLs and file names.
+	AlphabetURLSafe = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+)
+
+// letters used by RandPronounceable, consonants that read ambiguously (c, q, x, y) are left out.
+const (
+	consonants = "bdfghjklmnprstvwz"
+	vowels     = "aeiou"
+)
+
+// formatSets maps the placeholders understood by RandFormat to the characters they are replaced with.
+var formatSets = [256]string{
+	'9': "0123456789",
+	'a': "abcdefghijklmnopqrstuvwxyz",
+	'A': "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	'x': charset,
+	'X': "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+	'h': AlphabetHex,
+	'H': "0123456789ABCDEF",
+	'*': AlphabetBase62,
+	'c': consonants,
+	'v': vowels,
+}
+
+// RandStrFrom generates a random string of n characters picked uniformly from alphabet.
+// The alphabet is treated as bytes, so it should be ASCII. It panics if alphabet is empty and n > 0.
+func RandStrFrom(alphabet string, n int) string {
+	if len(alphabet) == 0 && n > 0 {
+		panic("entropy: empty alphabet")
+	}
+	r := lolXD.Get()
+	s := randStrFrom(r, alphabet, n)
+	lolXD.Put(r)
+	return s
+}
+
+/*
+RandFormat generates a random string following template, such as "XXXX-XXXX-9999" for invite codes.
+Each placeholder is replaced by a random character, everything else is kept as is:
+
+	9  digit 0-9
+	a  lowercase letter a-z
+	A  uppercase letter A-Z
+	x  lowercase letter or digit
+	X  uppercase letter or digit
+	h  lowercase hex digit
+	H  uppercase hex digit
+	*  base62, any letter or digit
+	c  lowercase consonant
+	v  lowercase vowel
+	\  escapes the following character, e.g. "\\x" is a literal x
+*/
+func RandFormat(template string) string {
+	r := lolXD.Get()
+	s := randFormat(r, template)
+	lolXD.Put(r)
+	return s
+}
+
+func randFormat(r *rand.Rand, template string) string {
+	buf := strBufs.Get()
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch set := formatSets[c]; {
+		case c == '\\' && i+1 < len(template):
+			i++
+			_ = buf.WriteByte(template[i])
+		case set != "":
+			_ = buf.WriteByte(set[uint32n(r, uint32(len(set)))])
+		default:
+			_ = buf.WriteByte(c)
+		}
+	}
+	s := buf.String()
+	strBufs.MustPut(buf)
+	return s
+}
+
+// RandPronounceable generates a random lowercase string of n letters alternating between consonants
+// and vowels, e.g. "bafolitu", for identifiers that need to be read out loud or remembered.
+func RandPronounceable(n int) string {
+	r := lolXD.Get()
+	s := randPronounceable(r, n)
+	lolXD.Put(r)
+	return s
+}
+
+func randPronounceable(r *rand.Rand, n int) string {
+	buf := strBufs.Get()
+	vowel := uint32n(r, 2) == 0
+	for i := 0; i < n; i++ {
+		set := consonants
+		if vowel {
+			set = vowels
+		}
+		_ = buf.WriteByte(set[uint32n(r, uint32(len(set)))])
+		vowel = !vowel
+	}
+	s := buf.String()
+	strBufs.MustPut(buf)
+	return s
+}
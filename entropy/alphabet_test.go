@@ -0,0 +1,130 @@
+package entropy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_RandStrFrom(t *testing.T) {
+	t.Parallel()
+	for _, alphabet := range []string{AlphabetHex, AlphabetCrockford, AlphabetBase58, AlphabetBase62, AlphabetURLSafe, "01"} {
+		for _, alphabetRune := range alphabet {
+			if strings.Count(alphabet, string(alphabetRune)) != 1 {
+				t.Fatalf("[FAIL] alphabet %q repeats %q", alphabet, alphabetRune)
+			}
+		}
+		counts := make([]int, len(alphabet))
+		s := RandStrFrom(alphabet, draws)
+		if len(s) != draws {
+			t.Fatalf("[FAIL] RandStrFrom returned %d characters, expected %d", len(s), draws)
+		}
+		for i := 0; i < len(s); i++ {
+			idx := strings.IndexByte(alphabet, s[i])
+			if idx < 0 {
+				t.Fatalf("[FAIL] RandStrFrom(%q) returned a character outside the alphabet: %q", alphabet, s[i])
+			}
+			counts[idx]++
+		}
+		checkUniform(t, fmt.Sprintf("RandStrFrom(%d characters)", len(alphabet)), counts)
+	}
+
+	if RandStrFrom("a", 5) != "aaaaa" || RandStrFrom("", 0) != "" {
+		t.Errorf("[FAIL] RandStrFrom edge cases")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("[FAIL] RandStrFrom with an empty alphabet should panic")
+		}
+	}()
+	RandStrFrom("", 1)
+}
+
+func Test_RandFormat(t *testing.T) {
+	t.Parallel()
+	for i := 0; i < 1000; i++ {
+		s := RandFormat("XXXX-XXXX-9999")
+		if len(s) != 14 || s[4] != '-' || s[9] != '-' {
+			t.Fatalf("[FAIL] RandFormat didn't keep the template's literals: %q", s)
+		}
+		for j, c := range s {
+			switch {
+			case j == 4 || j == 9:
+			case j > 9 && !strings.ContainsRune("0123456789", c),
+				j < 9 && !strings.ContainsRune(formatSets['X'], c):
+				t.Fatalf("[FAIL] RandFormat returned %q for template XXXX-XXXX-9999", s)
+			}
+		}
+	}
+
+	s := RandFormat(`key_\x\9\\-aAhH*cv\`)
+	if !strings.HasPrefix(s, `key_x9\-`) || !strings.HasSuffix(s, `\`) || len(s) != 16 {
+		t.Fatalf("[FAIL] RandFormat escaping is broken: %q", s)
+	}
+	for i, placeholder := range "aAhH*cv" {
+		if !strings.ContainsRune(formatSets[placeholder], rune(s[8+i])) {
+			t.Errorf("[FAIL] placeholder %q was replaced with %q", placeholder, s[8+i])
+		}
+	}
+
+	if RandFormat("") != "" || RandFormat("---") != "---" {
+		t.Errorf("[FAIL] RandFormat with no placeholders should return the template")
+	}
+
+	counts := make([]int, 10)
+	for _, c := range RandFormat(strings.Repeat("9", draws)) {
+		counts[c-'0']++
+	}
+	checkUniform(t, "RandFormat(9)", counts)
+}
+
+func Test_RandPronounceable(t *testing.T) {
+	t.Parallel()
+	startsWithVowel := 0
+	for i := 0; i < 1000; i++ {
+		s := RandPronounceable(9)
+		if len(s) != 9 {
+			t.Fatalf("[FAIL] RandPronounceable(9) returned %d letters", len(s))
+		}
+		vowel := strings.IndexByte(vowels, s[0]) >= 0
+		if vowel {
+			startsWithVowel++
+		}
+		for j := 0; j < len(s); j++ {
+			set := consonants
+			if vowel {
+				set = vowels
+			}
+			if strings.IndexByte(set, s[j]) < 0 {
+				t.Fatalf("[FAIL] RandPronounceable doesn't alternate consonants and vowels: %q", s)
+			}
+			vowel = !vowel
+		}
+	}
+	if startsWithVowel == 0 || startsWithVowel == 1000 {
+		t.Errorf("[FAIL] RandPronounceable always starts with the same kind of letter")
+	}
+}
+
+func Benchmark_RandStrFrom(b *testing.B) {
+	for _, size := range []int{25, 55, 500} {
+		b.Run(fmt.Sprintf("base58/len%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				RandStrFrom(AlphabetBase58, size)
+			}
+		})
+	}
+	b.Run("RandFormat", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			RandFormat("XXXX-XXXX-9999")
+		}
+	})
+	b.Run("RandPronounceable", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			RandPronounceable(12)
+		}
+	})
+}
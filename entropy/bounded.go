@@ -0,0 +1,62 @@
+package entropy
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// uint64n returns a uniform random number in [0, n) from r using Lemire's nearly divisionless method:
+// the high half of a 128 bit product is the result, and the (rarely taken) rejection step removes the bias
+// that plain modulo reduction would introduce.
+func uint64n(r *rand.Rand, n uint64) uint64 {
+	hi, lo := bits.Mul64(r.Uint64(), n)
+	if lo < n {
+		thresh := -n % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(r.Uint64(), n)
+		}
+	}
+	return hi
+}
+
+// uint32n is the 32 bit version of uint64n, used where speed matters and n is known to be small.
+func uint32n(r *rand.Rand, n uint32) uint32 {
+	prod := uint64(r.Uint32()) * uint64(n)
+	if low := uint32(prod); low < n {
+		thresh := -n % n
+		for low < thresh {
+			prod = uint64(r.Uint32()) * uint64(n)
+			low = uint32(prod)
+		}
+	}
+	return uint32(prod >> 32)
+}
+
+// Uint64n returns a uniform random uint64 in [0, n) without modulo bias. It panics if n == 0.
+func Uint64n(n uint64) uint64 {
+	if n == 0 {
+		panic("entropy: invalid argument to Uint64n")
+	}
+	r := lolXD.Get()
+	v := uint64n(r, n)
+	lolXD.Put(r)
+	return v
+}
+
+// Int63n returns a uniform random int64 in [0, n) without modulo bias. It panics if n <= 0.
+func Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("entropy: invalid argument to Int63n")
+	}
+	return int64(Uint64n(uint64(n)))
+}
+
+// IntRange returns a uniform random int in [min, max) without modulo bias. It panics if max <= min.
+// The full range of int is supported, e.g. IntRange(math.MinInt, math.MaxInt).
+func IntRange(min, max int) int {
+	if max <= min {
+		panic("entropy: invalid argument to IntRange")
+	}
+	// computed in uint64 so that the width of ranges spanning zero can't overflow.
+	return min + int(Uint64n(uint64(max)-uint64(min)))
+}
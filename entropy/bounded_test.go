@@ -0,0 +1,139 @@
+package entropy
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// chiSquaredLimit approximates the 99.99th percentile of the chi-squared distribution with df degrees
+// of freedom (Wilson-Hilferty), so that a uniform generator fails a single check about once in 10,000 runs.
+func chiSquaredLimit(df int) float64 {
+	const z = 3.719
+	k := float64(df)
+	return k * math.Pow(1-2/(9*k)+z*math.Sqrt(2/(9*k)), 3)
+}
+
+// chiSquared returns the chi-squared statistic of counts against a uniform distribution.
+func chiSquared(counts []int) float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	expected := float64(total) / float64(len(counts))
+	var chi float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi += d * d / expected
+	}
+	return chi
+}
+
+func checkUniform(t *testing.T, name string, counts []int) {
+	t.Helper()
+	chi, limit := chiSquared(counts), chiSquaredLimit(len(counts)-1)
+	if chi > limit {
+		t.Errorf("%s is not uniform: chi-squared %.2f > %.2f", name, chi, limit)
+		return
+	}
+	t.Logf("%s: chi-squared %.2f <= %.2f", name, chi, limit)
+}
+
+const draws = 500000
+
+func Test_Uint64n(t *testing.T) {
+	t.Parallel()
+	for _, n := range []uint64{1, 2, 3, 7, 10, 36, 62, 100} {
+		counts := make([]int, n)
+		for i := 0; i < draws; i++ {
+			v := Uint64n(n)
+			if v >= n {
+				t.Fatalf("Uint64n(%d) returned %d", n, v)
+			}
+			counts[v]++
+		}
+		if n > 1 {
+			checkUniform(t, "Uint64n", counts)
+		}
+	}
+
+	// a bound just over 2^63 would make modulo reduction pick the lower half twice as often
+	const big = 1<<63 + 1<<62
+	lower := 0
+	for i := 0; i < draws; i++ {
+		if Uint64n(big) < 1<<62 {
+			lower++
+		}
+	}
+	checkUniform(t, "Uint64n(1<<63 + 1<<62) lower third", []int{lower, (draws - lower) / 2, (draws - lower) / 2})
+}
+
+func Test_Int63nIntRange(t *testing.T) {
+	t.Parallel()
+	counts := make([]int, 9)
+	for i := 0; i < draws; i++ {
+		v := IntRange(-4, 5)
+		if v < -4 || v >= 5 {
+			t.Fatalf("IntRange(-4, 5) returned %d", v)
+		}
+		counts[v+4]++
+	}
+	checkUniform(t, "IntRange", counts)
+
+	counts = make([]int, 13)
+	for i := 0; i < draws; i++ {
+		counts[Int63n(13)]++
+	}
+	checkUniform(t, "Int63n", counts)
+
+	for i := 0; i < 1000; i++ {
+		if v := IntRange(math.MinInt, math.MaxInt); v == math.MaxInt {
+			t.Fatalf("IntRange(MinInt, MaxInt) returned its exclusive upper bound")
+		}
+		if v := IntRange(math.MaxInt-1, math.MaxInt); v != math.MaxInt-1 {
+			t.Fatalf("IntRange(MaxInt-1, MaxInt) returned %d", v)
+		}
+	}
+
+	for name, f := range map[string]func(){
+		"Uint64n(0)":      func() { Uint64n(0) },
+		"Int63n(0)":       func() { Int63n(0) },
+		"Int63n(-1)":      func() { Int63n(-1) },
+		"IntRange(1, 1)":  func() { IntRange(1, 1) },
+		"IntRange(2, -2)": func() { IntRange(2, -2) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s should panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func Test_RandStrUniform(t *testing.T) {
+	t.Parallel()
+	for _, set := range []string{charset, charsetWithUpper} {
+		counts := make([]int, len(set))
+		s := randStr(len(set) == len(charsetWithUpper), draws)
+		for _, c := range s {
+			counts[strings.IndexRune(set, c)]++
+		}
+		checkUniform(t, "randStr", counts)
+	}
+
+	choices := []string{"a", "b", "c", "d", "e", "f", "g"}
+	counts := make([]int, len(choices))
+	for i := 0; i < draws; i++ {
+		counts[RandomStrChoice(choices)[0]-'a']++
+	}
+	checkUniform(t, "RandomStrChoice", counts)
+}
+
+func Benchmark_Uint64n(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		Uint64n(1000)
+	}
+}
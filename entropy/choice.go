@@ -0,0 +1,176 @@
+package entropy
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// ErrInvalidWeights is returned by [NewWeightedChoice] when the weights can't be used to build a distribution.
+var ErrInvalidWeights = errors.New("entropy: invalid weights")
+
+// Choice returns a random item from s, or the zero value of T if s is empty.
+func Choice[T any](s []T) T {
+	r := lolXD.Get()
+	v := choice(r, s)
+	lolXD.Put(r)
+	return v
+}
+
+func choice[T any](r *rand.Rand, s []T) T {
+	if len(s) == 0 {
+		var zero T
+		return zero
+	}
+	return s[uint64n(r, uint64(len(s)))]
+}
+
+// Shuffle randomizes the order of s in place using the Fisher-Yates algorithm,
+// such that every permutation is equally likely.
+func Shuffle[T any](s []T) {
+	r := lolXD.Get()
+	shuffle(r, s)
+	lolXD.Put(r)
+}
+
+func shuffle[T any](r *rand.Rand, s []T) {
+	for i := len(s) - 1; i > 0; i-- {
+		j := uint64n(r, uint64(i+1))
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// Sample returns k distinct items picked at random from s (without replacement), in random order.
+// If k >= len(s), all of s is returned in random order. s itself is not modified.
+func Sample[T any](s []T, k int) []T {
+	if k <= 0 {
+		return nil
+	}
+	r := lolXD.Get()
+	picked := sample(r, s, k)
+	lolXD.Put(r)
+	return picked
+}
+
+func sample[T any](r *rand.Rand, s []T, k int) []T {
+	if k <= 0 {
+		return nil
+	}
+	k = min(k, len(s))
+	if k < len(s)/4 {
+		return sampleSparse(r, s, k)
+	}
+	pool := make([]T, len(s))
+	copy(pool, s)
+	// a partial Fisher-Yates shuffle, only the first k positions need to be settled.
+	for i := 0; i < k; i++ {
+		j := i + int(uint64n(r, uint64(len(pool)-i)))
+		pool[i], pool[j] = pool[j], pool[i]
+	}
+	return pool[:k:k]
+}
+
+// sampleSparse runs the same partial Fisher-Yates shuffle as sample, and picks the same items for the same
+// generator, but records only the positions it swapped instead of copying all of s.
+func sampleSparse[T any](r *rand.Rand, s []T, k int) []T {
+	picked := make([]T, k)
+	swapped := make(map[int]int, k)
+	at := func(i int) int {
+		if j, ok := swapped[i]; ok {
+			return j
+		}
+		return i
+	}
+	for i := 0; i < k; i++ {
+		j := i + int(uint64n(r, uint64(len(s)-i)))
+		picked[i] = s[at(j)]
+		swapped[j] = at(i)
+	}
+	return picked
+}
+
+// WeightedChoice picks items at random in proportion to their weights, in constant time per pick
+// using Vose's alias method. It is safe for concurrent use once built.
+type WeightedChoice[T any] struct {
+	items []T
+	// prob is the chance of keeping the column that was rolled, instead of its alias.
+	prob  []float64
+	alias []int
+}
+
+// NewWeightedChoice builds a [WeightedChoice] over items, where each item is picked with a probability
+// proportional to the weight at the same index. Weights must be finite and non-negative, and at least one
+// must be positive; otherwise [ErrInvalidWeights] is returned.
+func NewWeightedChoice[T any](items []T, weights []float64) (*WeightedChoice[T], error) {
+	if len(items) != len(weights) || len(items) == 0 {
+		return nil, ErrInvalidWeights
+	}
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, ErrInvalidWeights
+		}
+		total += w
+	}
+	if total <= 0 || math.IsInf(total, 0) {
+		return nil, ErrInvalidWeights
+	}
+
+	n := len(items)
+	wc := &WeightedChoice[T]{items: append([]T(nil), items...), prob: make([]float64, n), alias: make([]int, n)}
+	scaled := make([]float64, n)
+	var small, large []int
+	for i, w := range weights {
+		scaled[i] = w / total * float64(n)
+		if scaled[i] < 1 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		wc.prob[s], wc.alias[s] = scaled[s], l
+		// the large column donates what the small one is missing
+		scaled[l] -= 1 - scaled[s]
+		if scaled[l] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+	// anything left over is 1 give or take floating point error.
+	for _, i := range append(small, large...) {
+		wc.prob[i], wc.alias[i] = 1, i
+	}
+	return wc, nil
+}
+
+// Pick returns a random item, with probability proportional to its weight.
+func (wc *WeightedChoice[T]) Pick() T {
+	r := lolXD.Get()
+	v := wc.pick(r)
+	lolXD.Put(r)
+	return v
+}
+
+// PickFrom is like Pick, but draws from src instead of the shared generators.
+func (wc *WeightedChoice[T]) PickFrom(src *Source) T {
+	src.mu.Lock()
+	v := wc.pick(src.r)
+	src.mu.Unlock()
+	return v
+}
+
+func (wc *WeightedChoice[T]) pick(r *rand.Rand) T {
+	i := uint64n(r, uint64(len(wc.items)))
+	if r.Float64() < wc.prob[i] {
+		return wc.items[i]
+	}
+	return wc.items[wc.alias[i]]
+}
+
+// Len returns the number of items to pick from.
+func (wc *WeightedChoice[T]) Len() int {
+	return len(wc.items)
+}
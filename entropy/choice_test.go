@@ -0,0 +1,223 @@
+package entropy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func Test_Choice(t *testing.T) {
+	t.Parallel()
+	items := []int{10, 11, 12, 13, 14, 15}
+	counts := make([]int, len(items))
+	for i := 0; i < draws; i++ {
+		counts[Choice(items)-10]++
+	}
+	checkUniform(t, "Choice", counts)
+
+	if Choice([]int(nil)) != 0 || Choice([]*int{}) != nil {
+		t.Errorf("[FAIL] Choice of an empty slice should return the zero value")
+	}
+	if RandomStrChoice(nil) != "" {
+		t.Errorf("[FAIL] RandomStrChoice(nil) should be empty")
+	}
+}
+
+func Test_Shuffle(t *testing.T) {
+	t.Parallel()
+	// every one of the 24 permutations of 4 items should come up equally often
+	perms := make(map[[4]byte]int)
+	for i := 0; i < draws/4; i++ {
+		s := []byte{'a', 'b', 'c', 'd'}
+		Shuffle(s)
+		perms[[4]byte(s)]++
+	}
+	if len(perms) != 24 {
+		t.Fatalf("[FAIL] Shuffle produced %d distinct permutations of 4 items, expected 24", len(perms))
+	}
+	counts := make([]int, 0, len(perms))
+	for _, c := range perms {
+		counts = append(counts, c)
+	}
+	checkUniform(t, "Shuffle", counts)
+
+	s := make([]int, 1000)
+	for i := range s {
+		s[i] = i
+	}
+	Shuffle(s)
+	sorted := sort.IntsAreSorted(s)
+	sort.Ints(s)
+	for i := range s {
+		if s[i] != i {
+			t.Fatalf("[FAIL] Shuffle lost or duplicated items")
+		}
+	}
+	if sorted {
+		t.Errorf("[FAIL] Shuffle left 1000 items in order")
+	}
+
+	Shuffle([]int(nil))
+	Shuffle([]int{1})
+}
+
+func Test_Sample(t *testing.T) {
+	t.Parallel()
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	counts := make([]int, len(items))
+	for i := 0; i < draws/3; i++ {
+		got := Sample(items, 3)
+		if len(got) != 3 {
+			t.Fatalf("[FAIL] Sample(items, 3) returned %d items", len(got))
+		}
+		if got[0] == got[1] || got[0] == got[2] || got[1] == got[2] {
+			t.Fatalf("[FAIL] Sample returned duplicates: %v", got)
+		}
+		for _, v := range got {
+			counts[v]++
+		}
+	}
+	checkUniform(t, "Sample", counts)
+
+	for i := range items {
+		if items[i] != i {
+			t.Fatalf("[FAIL] Sample modified its input")
+		}
+	}
+
+	all := Sample(items, 100)
+	sort.Ints(all)
+	if len(all) != len(items) {
+		t.Fatalf("[FAIL] Sample(items, 100) returned %d items, expected %d", len(all), len(items))
+	}
+	for i := range all {
+		if all[i] != i {
+			t.Fatalf("[FAIL] Sample(items, 100) should return every item once, got %v", all)
+		}
+	}
+
+	if Sample(items, 0) != nil || Sample(items, -1) != nil || len(Sample([]int(nil), 3)) != 0 {
+		t.Errorf("[FAIL] Sample should return nothing for k <= 0 or an empty slice")
+	}
+
+	// small samples of large slices don't copy the slice, but must pick exactly what the full shuffle would.
+	big := make([]int, 1000)
+	for i := range big {
+		big[i] = i
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		dense := sample(rand.New(rand.NewSource(seed)), big, 300)        //nolint:gosec
+		sparse := sampleSparse(rand.New(rand.NewSource(seed)), big, 300) //nolint:gosec
+		for i := range dense {
+			if dense[i] != sparse[i] {
+				t.Fatalf("[FAIL] seed %d: sparse sample diverged at %d: %d != %d", seed, i, sparse[i], dense[i])
+			}
+		}
+	}
+	seen := make(map[int]struct{})
+	for _, v := range Sample(big, 10) {
+		if _, dup := seen[v]; dup {
+			t.Fatalf("[FAIL] Sample returned duplicates from a large slice: %d", v)
+		}
+		seen[v] = struct{}{}
+	}
+	if len(seen) != 10 {
+		t.Errorf("[FAIL] Sample(big, 10) returned %d items", len(seen))
+	}
+}
+
+func Test_WeightedChoice(t *testing.T) {
+	t.Parallel()
+	items := []string{"a", "b", "c", "d", "e"}
+	weights := []float64{1, 2, 3, 4, 0}
+	wc, err := NewWeightedChoice(items, weights)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if wc.Len() != len(items) {
+		t.Errorf("[FAIL] Len() = %d, expected %d", wc.Len(), len(items))
+	}
+
+	counts := make([]int, len(items))
+	for i := 0; i < draws; i++ {
+		counts[wc.Pick()[0]-'a']++
+	}
+	if counts[4] != 0 {
+		t.Errorf("[FAIL] an item with weight 0 was picked %d times", counts[4])
+	}
+	// chi-squared against the expected proportions, ignoring the zero weight item
+	var chi float64
+	for i, w := range weights[:4] {
+		expected := draws * w / 10
+		d := float64(counts[i]) - expected
+		chi += d * d / expected
+	}
+	if limit := chiSquaredLimit(3); chi > limit {
+		t.Errorf("[FAIL] WeightedChoice doesn't follow its weights: %v, chi-squared %.2f > %.2f", counts, chi, limit)
+	}
+
+	single, err := NewWeightedChoice([]int{7}, []float64{0.5})
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	for i := 0; i < 100; i++ {
+		if single.Pick() != 7 {
+			t.Fatalf("[FAIL] a single item WeightedChoice picked something else")
+		}
+	}
+
+	for name, w := range map[string][]float64{
+		"mismatched": {1, 2},
+		"negative":   {1, -1, 1},
+		"zero":       {0, 0, 0},
+		"NaN":        {1, math.NaN(), 1},
+		"Inf":        {1, math.Inf(1), 1},
+	} {
+		if _, err = NewWeightedChoice([]int{1, 2, 3}, w); !errors.Is(err, ErrInvalidWeights) {
+			t.Errorf("[FAIL] %s weights: expected ErrInvalidWeights, got %v", name, err)
+		}
+	}
+	if _, err = NewWeightedChoice([]int{}, []float64{}); !errors.Is(err, ErrInvalidWeights) {
+		t.Errorf("[FAIL] empty items: expected ErrInvalidWeights, got %v", err)
+	}
+}
+
+func Benchmark_WeightedChoice(b *testing.B) {
+	weights := make([]float64, 1000)
+	items := make([]int, len(weights))
+	for i := range weights {
+		items[i], weights[i] = i, float64(i%7+1)
+	}
+	wc, err := NewWeightedChoice(items, weights)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		wc.Pick()
+	}
+}
+
+func Benchmark_Shuffle(b *testing.B) {
+	s := make([]int, 100)
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		Shuffle(s)
+	}
+}
+
+func Benchmark_Sample(b *testing.B) {
+	s := make([]int, 100000)
+	for _, k := range []int{10, len(s) / 2} {
+		b.Run(fmt.Sprint(k), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				Sample(s, k)
+			}
+		})
+	}
+}
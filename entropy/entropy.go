@@ -7,8 +7,6 @@ import (
 	"sync"
 	"time"
 
-	"nullprogram.com/x/rng"
-
 	"github.com/yunginnanet/common/pool"
 )
 
@@ -17,10 +15,18 @@ type randPool struct {
 }
 
 func (p *randPool) Get() *rand.Rand {
+	if src := pinned.Load(); src != nil {
+		return src.derive()
+	}
 	return p.Pool.Get().(*rand.Rand)
 }
 
 func (p *randPool) Put(r *rand.Rand) {
+	// generators derived from a pinned seed are predictable and must never make it into the pool,
+	// regardless of whether the seed is still pinned when they are returned.
+	if isDerived(r) {
+		return
+	}
 	p.Pool.Put(r)
 }
 
@@ -28,10 +34,7 @@ var (
 	lolXD = randPool{
 		Pool: sync.Pool{
 			New: func() interface{} {
-				sm64 := new(rng.SplitMix64)
-				sm64.Seed(GetCryptoSeed())
-				prng := rand.New(sm64) //nolint:gosec
-				return prng
+				return newSplitMix(GetCryptoSeed())
 			},
 		},
 	}
@@ -56,11 +59,9 @@ func ReleaseRand(r *rand.Rand) {
 }
 
 // RandomStrChoice returns a random item from an input slice of strings.
+// See [Choice] for other types.
 func RandomStrChoice(choice []string) string {
-	if len(choice) > 0 {
-		return choice[RNGUint32()%uint32(len(choice))]
-	}
-	return ""
+	return Choice(choice)
 }
 
 // GetCryptoSeed returns a random int64 derived from crypto/rand.
@@ -74,13 +75,15 @@ func GetCryptoSeed() int64 {
 // GetOptimizedRand returns a pointer to a *new* rand.Rand which uses GetCryptoSeed to seed an rng.SplitMix64.
 // Does not use the global/shared instance of a splitmix64 rng, but instead creates a new one.
 func GetOptimizedRand() *rand.Rand {
-	r := new(rng.SplitMix64)
-	r.Seed(GetCryptoSeed())
-	return rand.New(r) //nolint:gosec
+	if src := pinned.Load(); src != nil {
+		return src.derive()
+	}
+	return newSplitMix(GetCryptoSeed())
 }
 
 // GetSharedRand returns a pointer to our shared optimized rand.Rand which uses crypto/rand to seed a splitmix64 rng.
 // WARNING - RACY - This is not thread safe, and should only be used in a single-threaded context.
+// Use [NewLockedRand] when a generator needs to be shared between goroutines.
 func GetSharedRand() *rand.Rand {
 	getSharedRand.Do(func() {
 		setSharedRand()
@@ -133,6 +136,7 @@ var strBufs = pool.NewBufferFactory()
 
 // RandStr generates a random alphanumeric string with a max length of size.
 // Alpha charset used is a-z all lowercase.
+// It is not suitable for tokens or anything else that must not be guessed, see the entropy/secure package for that.
 func RandStr(size int) string {
 	return randStr(false, size)
 }
@@ -164,18 +168,22 @@ randStr is an overoptimized (read: dummy fast) random string generator.
 	2 alloc/op and ~500 bytes/op with byte buffers.
 */
 func randStr(upper bool, size int) string {
-	buf := strBufs.Get()
-	r := lolXD.Get()
-	for i := 0; i != size; i++ {
-		ui32 := int(r.Uint32())
-		switch upper {
-		case true:
-			_ = buf.WriteByte(charsetWithUpper[ui32%len(charsetWithUpper)])
-		case false:
-			_ = buf.WriteByte(charset[ui32%len(charset)])
-		}
+	set := charset
+	if upper {
+		set = charsetWithUpper
 	}
+	r := lolXD.Get()
+	s := randStrFrom(r, set, size)
 	lolXD.Put(r)
+	return s
+}
+
+func randStrFrom(r *rand.Rand, set string, size int) string {
+	buf := strBufs.Get()
+	n := uint32(len(set))
+	for i := 0; i < size; i++ {
+		_ = buf.WriteByte(set[uint32n(r, n)])
+	}
 	s := buf.String()
 	strBufs.MustPut(buf)
 	return s
@@ -0,0 +1,160 @@
+package entropy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// KSUIDEpoch is the Unix time (in seconds) that KSUID timestamps count from, 2014-05-13T16:53:20Z.
+const KSUIDEpoch = 1400000000
+
+// KSUID is a K-Sortable Unique IDentifier: a 32 bit timestamp in seconds since [KSUIDEpoch] followed by
+// a 128 bit random payload, encoded as 27 base62 characters. The zero value is the zero KSUID.
+type KSUID [20]byte
+
+// NewKSUID returns a KSUID for the current time, with its payload from the pooled generators.
+// Use [NewSecureKSUID] for KSUIDs that must not be guessed.
+func NewKSUID() KSUID {
+	return newKSUID(time.Now(), false)
+}
+
+// NewSecureKSUID is like [NewKSUID], but its payload comes from crypto/rand.
+func NewSecureKSUID() KSUID {
+	return newKSUID(time.Now(), true)
+}
+
+func newKSUID(now time.Time, secure bool) (k KSUID) {
+	binary.BigEndian.PutUint32(k[:4], uint32(now.Unix()-KSUIDEpoch))
+	fillRandom(k[4:], secure)
+	return k
+}
+
+// base62Dec maps the characters of AlphabetBase62 to their values. Anything else is 0xff.
+var base62Dec = func() (dec [256]byte) {
+	for i := range dec {
+		dec[i] = 0xff
+	}
+	for i := 0; i < len(AlphabetBase62); i++ {
+		dec[AlphabetBase62[i]] = byte(i)
+	}
+	return dec
+}()
+
+// ParseKSUID parses the 27 character form of a KSUID.
+func ParseKSUID(s string) (k KSUID, err error) {
+	if len(s) != 27 {
+		return k, fmt.Errorf("%w: KSUID must be 27 characters, got %d", ErrInvalidID, len(s))
+	}
+	// the 160 bit number as big-endian 32 bit words, multiplied by 62 for every character.
+	var words [5]uint32
+	for i := 0; i < len(s); i++ {
+		v := base62Dec[s[i]]
+		if v == 0xff {
+			return k, fmt.Errorf("%w: malformed KSUID %q", ErrInvalidID, s)
+		}
+		carry := uint64(v)
+		for w := len(words) - 1; w >= 0; w-- {
+			carry += uint64(words[w]) * 62
+			words[w] = uint32(carry)
+			carry >>= 32
+		}
+		if carry != 0 {
+			return k, fmt.Errorf("%w: KSUID %q is out of range", ErrInvalidID, s)
+		}
+	}
+	for w, word := range words {
+		binary.BigEndian.PutUint32(k[w*4:], word)
+	}
+	return k, nil
+}
+
+// MustParseKSUID is like [ParseKSUID] but panics if s can't be parsed.
+func MustParseKSUID(s string) KSUID {
+	k, err := ParseKSUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+// String returns the 27 character base62 form of k.
+func (k KSUID) String() string {
+	var buf [27]byte
+	k.encode(buf[:])
+	return string(buf[:])
+}
+
+func (k KSUID) encode(dst []byte) {
+	var words [5]uint32
+	for w := range words {
+		words[w] = binary.BigEndian.Uint32(k[w*4:])
+	}
+	// long division of the 160 bit number by 62, one digit at a time from the right.
+	for i := len(dst) - 1; i >= 0; i-- {
+		var rem uint64
+		for w := range words {
+			cur := rem<<32 | uint64(words[w])
+			words[w] = uint32(cur / 62)
+			rem = cur % 62
+		}
+		dst[i] = AlphabetBase62[rem]
+	}
+}
+
+// Timestamp returns the raw timestamp of k, in seconds since [KSUIDEpoch].
+func (k KSUID) Timestamp() uint32 {
+	return binary.BigEndian.Uint32(k[:4])
+}
+
+// Time returns the creation time of k, with second precision.
+func (k KSUID) Time() time.Time {
+	return time.Unix(int64(k.Timestamp())+KSUIDEpoch, 0)
+}
+
+// Payload returns the random part of k.
+func (k KSUID) Payload() []byte {
+	return k[4:]
+}
+
+// IsZero reports whether k is the zero KSUID.
+func (k KSUID) IsZero() bool {
+	return k == KSUID{}
+}
+
+// Compare returns -1, 0 or +1 depending on whether k sorts before, the same as, or after other.
+func (k KSUID) Compare(other KSUID) int {
+	return bytes.Compare(k[:], other[:])
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (k KSUID) MarshalText() ([]byte, error) {
+	buf := make([]byte, 27)
+	k.encode(buf)
+	return buf, nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (k *KSUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseKSUID(string(text))
+	if err != nil {
+		return err
+	}
+	*k = parsed
+	return nil
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+func (k KSUID) MarshalBinary() ([]byte, error) {
+	return k[:], nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (k *KSUID) UnmarshalBinary(data []byte) error {
+	if len(data) != len(k) {
+		return fmt.Errorf("%w: KSUID must be %d bytes, got %d", ErrInvalidID, len(k), len(data))
+	}
+	copy(k[:], data)
+	return nil
+}
@@ -0,0 +1,115 @@
+package entropy
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_KSUID(t *testing.T) {
+	t.Parallel()
+	// the example from segmentio/ksuid
+	k := MustParseKSUID("0ujtsYcgvSTl8PAuAdqWYSMnLOv")
+	if k.Timestamp() != 107608047 {
+		t.Errorf("[FAIL] expected a timestamp of 107608047, got %d", k.Timestamp())
+	}
+	if p := strings.ToUpper(hex.EncodeToString(k.Payload())); p != "B5A1CD34B5F99D1154FB6853345C9735" {
+		t.Errorf("[FAIL] expected a payload of B5A1CD34B5F99D1154FB6853345C9735, got %s", p)
+	}
+	if k.String() != "0ujtsYcgvSTl8PAuAdqWYSMnLOv" {
+		t.Errorf("[FAIL] round trip of the example returned %s", k)
+	}
+	if k.Time().Unix() != 107608047+KSUIDEpoch {
+		t.Errorf("[FAIL] Time() returned %s", k.Time())
+	}
+
+	var max KSUID
+	for i := range max {
+		max[i] = 0xff
+	}
+	if max.String() != "aWgEPTl1tmebfsQzFP4bxwgy80V" || MustParseKSUID(max.String()) != max {
+		t.Errorf("[FAIL] the largest KSUID encodes as %s", max)
+	}
+	var zero KSUID
+	if !zero.IsZero() || zero.String() != strings.Repeat("0", 27) {
+		t.Errorf("[FAIL] the zero KSUID encodes as %s", zero)
+	}
+
+	before := time.Now()
+	seen := make(map[KSUID]struct{})
+	for _, gen := range []func() KSUID{NewKSUID, NewSecureKSUID} {
+		for i := 0; i < 10000; i++ {
+			id := gen()
+			if d := id.Time().Sub(before); d < -time.Second || d > time.Minute {
+				t.Fatalf("[FAIL] KSUID time is off by %s", d)
+			}
+			if _, dup := seen[id]; dup {
+				t.Fatalf("[FAIL] hit a duplicate! %s", id)
+			}
+			seen[id] = struct{}{}
+			if parsed := MustParseKSUID(id.String()); parsed != id {
+				t.Fatalf("[FAIL] round trip of %s returned %s", id, parsed)
+			}
+		}
+	}
+
+	older, newer := newKSUID(before.Add(-time.Hour), false), newKSUID(before, false)
+	if older.Compare(newer) >= 0 || newer.Compare(older) <= 0 || older.String() >= newer.String() {
+		t.Errorf("[FAIL] KSUIDs should sort by time")
+	}
+
+	for _, s := range []string{
+		"",
+		"0ujtsYcgvSTl8PAuAdqWYSMnLO",
+		"aWgEPTl1tmebfsQzFP4bxwgy80W",
+		"zzzzzzzzzzzzzzzzzzzzzzzzzzz",
+		"0ujtsYcgvSTl8PAuAdqWYSMnLO-",
+	} {
+		if _, err := ParseKSUID(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("[FAIL] ParseKSUID(%q): expected ErrInvalidID, got %v", s, err)
+		}
+	}
+}
+
+func Test_KSUIDMarshal(t *testing.T) {
+	t.Parallel()
+	in := []KSUID{NewKSUID(), NewSecureKSUID()}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	var out []KSUID
+	if err = json.Unmarshal(data, &out); err != nil || len(out) != 2 || out[0] != in[0] || out[1] != in[1] {
+		t.Errorf("[FAIL] JSON round trip failed: %v", err)
+	}
+
+	bin, _ := in[0].MarshalBinary()
+	var k KSUID
+	if err = k.UnmarshalBinary(bin); err != nil || k != in[0] {
+		t.Errorf("[FAIL] binary round trip failed: %v", err)
+	}
+	if err = k.UnmarshalBinary(bin[:16]); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("[FAIL] expected ErrInvalidID, got %v", err)
+	}
+	if err = k.UnmarshalText([]byte("bogus")); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("[FAIL] expected ErrInvalidID, got %v", err)
+	}
+}
+
+func Benchmark_KSUID(b *testing.B) {
+	b.Run("New", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			NewKSUID()
+		}
+	})
+	b.Run("String", func(b *testing.B) {
+		k := NewKSUID()
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_ = k.String()
+		}
+	})
+}
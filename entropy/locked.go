@@ -0,0 +1,188 @@
+package entropy
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"nullprogram.com/x/rng"
+)
+
+type shard struct {
+	mu sync.Mutex
+	r  *rand.Rand
+	// keeps neighbouring shards off of each other's cache line.
+	_ [48]byte
+}
+
+/*
+LockedRand is a thread-safe drop-in for *rand.Rand, backed by SplitMix64 generators seeded from crypto/rand.
+It implements the full method set of *rand.Rand as well as rand.Source64, so it can be handed to
+third-party code that calls it from many goroutines, or wrapped with rand.New.
+
+Rather than putting every caller behind one mutex, LockedRand is made of several independently locked
+generators (at least one per P, rounded up to a power of two). Each call takes the next shard round-robin,
+and moves on to the following one if that shard is busy.
+
+Note that a *rand.Rand created by rand.New(lockedRand) is still not safe for concurrent calls to its Read method.
+*/
+type LockedRand struct {
+	shards []shard
+	mask   uint32
+	next   atomic.Uint32
+}
+
+var _ rand.Source64 = (*LockedRand)(nil)
+
+// NewLockedRand returns a new [LockedRand]. The helpers being pinned with [PinSeed] applies here too.
+func NewLockedRand() *LockedRand {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) {
+		n <<= 1
+	}
+	lr := &LockedRand{shards: make([]shard, n), mask: uint32(n - 1)}
+	for i := range lr.shards {
+		lr.shards[i].r = GetOptimizedRand()
+	}
+	return lr
+}
+
+// lock returns a locked shard, preferring one that nobody else is holding.
+func (lr *LockedRand) lock() *shard {
+	start := lr.next.Add(1)
+	for i := uint32(0); i <= lr.mask; i++ {
+		s := &lr.shards[(start+i)&lr.mask]
+		if s.mu.TryLock() {
+			return s
+		}
+	}
+	s := &lr.shards[start&lr.mask]
+	s.mu.Lock()
+	return s
+}
+
+// Seed reseeds every shard deterministically from seed. Output is only reproducible when
+// the LockedRand is used from a single goroutine afterwards.
+func (lr *LockedRand) Seed(seed int64) {
+	for i := range lr.shards {
+		lr.shards[i].mu.Lock()
+	}
+	seeder := new(rng.SplitMix64)
+	seeder.Seed(seed)
+	for i := range lr.shards {
+		lr.shards[i].r = newSplitMix(seeder.Int63())
+	}
+	lr.next.Store(0)
+	for i := range lr.shards {
+		lr.shards[i].mu.Unlock()
+	}
+}
+
+// Int63 returns a non-negative pseudo-random 63-bit integer as an int64.
+func (lr *LockedRand) Int63() int64 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Int63()
+}
+
+// Uint32 returns a pseudo-random 32-bit value as a uint32.
+func (lr *LockedRand) Uint32() uint32 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Uint32()
+}
+
+// Uint64 returns a pseudo-random 64-bit value as a uint64.
+func (lr *LockedRand) Uint64() uint64 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Uint64()
+}
+
+// Int31 returns a non-negative pseudo-random 31-bit integer as an int32.
+func (lr *LockedRand) Int31() int32 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Int31()
+}
+
+// Int returns a non-negative pseudo-random int.
+func (lr *LockedRand) Int() int {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Int()
+}
+
+// Int63n returns, as an int64, a non-negative pseudo-random number in [0,n). It panics if n <= 0.
+func (lr *LockedRand) Int63n(n int64) int64 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Int63n(n)
+}
+
+// Int31n returns, as an int32, a non-negative pseudo-random number in [0,n). It panics if n <= 0.
+func (lr *LockedRand) Int31n(n int32) int32 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Int31n(n)
+}
+
+// Intn returns, as an int, a non-negative pseudo-random number in [0,n). It panics if n <= 0.
+func (lr *LockedRand) Intn(n int) int {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Intn(n)
+}
+
+// Float64 returns, as a float64, a pseudo-random number in [0.0,1.0).
+func (lr *LockedRand) Float64() float64 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Float64()
+}
+
+// Float32 returns, as a float32, a pseudo-random number in [0.0,1.0).
+func (lr *LockedRand) Float32() float32 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Float32()
+}
+
+// NormFloat64 returns a normally distributed float64 in the range [-math.MaxFloat64, +math.MaxFloat64]
+// with standard normal distribution (mean = 0, stddev = 1).
+func (lr *LockedRand) NormFloat64() float64 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.NormFloat64()
+}
+
+// ExpFloat64 returns an exponentially distributed float64 in the range (0, +math.MaxFloat64]
+// with an exponential distribution whose rate parameter (lambda) is 1 and whose mean is 1/lambda (1).
+func (lr *LockedRand) ExpFloat64() float64 {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.ExpFloat64()
+}
+
+// Perm returns, as a slice of n ints, a pseudo-random permutation of the integers in the half-open interval [0,n).
+func (lr *LockedRand) Perm(n int) []int {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Perm(n)
+}
+
+// Shuffle pseudo-randomizes the order of elements. n is the number of elements, and swap swaps the elements
+// with indexes i and j. It panics if n < 0.
+func (lr *LockedRand) Shuffle(n int, swap func(i, j int)) {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	s.r.Shuffle(n, swap)
+}
+
+// Read generates len(p) random bytes and writes them into p. It always returns len(p) and a nil error.
+func (lr *LockedRand) Read(p []byte) (n int, err error) {
+	s := lr.lock()
+	defer s.mu.Unlock()
+	return s.r.Read(p) //nolint:gosec
+}
@@ -0,0 +1,142 @@
+package entropy
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+func Test_LockedRand(t *testing.T) {
+	t.Parallel()
+	lr := NewLockedRand()
+	wg := &sync.WaitGroup{}
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, 33)
+			for n := 0; n < 500; n++ {
+				if v := lr.Intn(10); v < 0 || v >= 10 {
+					t.Errorf("[FAIL] Intn(10) returned %d", v)
+				}
+				if v := lr.Int31n(10); v < 0 || v >= 10 {
+					t.Errorf("[FAIL] Int31n(10) returned %d", v)
+				}
+				if v := lr.Int63n(10); v < 0 || v >= 10 {
+					t.Errorf("[FAIL] Int63n(10) returned %d", v)
+				}
+				if f := lr.Float64(); f < 0 || f >= 1 {
+					t.Errorf("[FAIL] Float64 returned %f", f)
+				}
+				if f := lr.Float32(); f < 0 || f >= 1 {
+					t.Errorf("[FAIL] Float32 returned %f", f)
+				}
+				if lr.Int() < 0 || lr.Int31() < 0 || lr.Int63() < 0 {
+					t.Errorf("[FAIL] Int, Int31 or Int63 returned a negative number")
+				}
+				lr.Uint32()
+				lr.Uint64()
+				lr.NormFloat64()
+				lr.ExpFloat64()
+				if n, err := lr.Read(buf); n != len(buf) || err != nil {
+					t.Errorf("[FAIL] Read returned %d, %v", n, err)
+				}
+				if len(lr.Perm(5)) != 5 {
+					t.Errorf("[FAIL] Perm(5) returned the wrong length")
+				}
+				s := []int{1, 2, 3}
+				lr.Shuffle(len(s), func(i, j int) { s[i], s[j] = s[j], s[i] })
+			}
+		}()
+	}
+	wg.Wait()
+
+	counts := make([]int, 11)
+	for i := 0; i < draws; i++ {
+		counts[lr.Intn(11)]++
+	}
+	checkUniform(t, "LockedRand.Intn", counts)
+}
+
+func Test_LockedRandSource(t *testing.T) {
+	t.Parallel()
+	lr := NewLockedRand()
+	var src rand.Source64 = lr
+	wrapped := rand.New(src) //nolint:gosec
+	wg := &sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 1000; n++ {
+				if v := wrapped.Intn(100); v < 0 || v >= 100 {
+					t.Errorf("[FAIL] rand.New(LockedRand).Intn(100) returned %d", v)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	one, two := NewLockedRand(), NewLockedRand()
+	one.Seed(99)
+	two.Seed(99)
+	for i := 0; i < 100; i++ {
+		if a, b := one.Uint64(), two.Uint64(); a != b {
+			t.Fatalf("[FAIL] LockedRands with the same seed diverged: %d != %d", a, b)
+		}
+	}
+}
+
+func Test_LockedRandPanic(t *testing.T) {
+	t.Parallel()
+	lr := NewLockedRand()
+	for _, f := range []func(){
+		func() { lr.Intn(0) },
+		func() { lr.Int63n(-1) },
+		func() { lr.Perm(-1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("[FAIL] expected a panic")
+				}
+			}()
+			f()
+		}()
+	}
+	// a panicking call must not leave its shard locked
+	for i := 0; i < len(lr.shards)*2; i++ {
+		lr.Uint64()
+	}
+}
+
+func Benchmark_LockedRand(b *testing.B) {
+	b.Run("LockedRand", func(b *testing.B) {
+		lr := NewLockedRand()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				lr.Uint64()
+			}
+		})
+	})
+	b.Run("lolXD", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				r := lolXD.Get()
+				r.Uint64()
+				lolXD.Put(r)
+			}
+		})
+	})
+	b.Run("mutex", func(b *testing.B) {
+		mu := &sync.Mutex{}
+		r := GetOptimizedRand()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				mu.Lock()
+				r.Uint64()
+				mu.Unlock()
+			}
+		})
+	})
+}
@@ -0,0 +1,187 @@
+// Package secure mirrors the helpers in the entropy package, but draws every value directly from crypto/rand.
+//
+// The entropy package is backed by SplitMix64 generators that are merely seeded from crypto/rand;
+// it is fast, but its output is predictable to anyone who observes enough of it. Use this package instead
+// for anything that must not be guessed, such as tokens, session IDs, passwords and nonces.
+//
+// Reads from crypto/rand are buffered in small pooled chunks, and every value is generated without
+// modulo bias. If the operating system's random source fails, functions in this package panic rather
+// than return predictable output.
+package secure
+
+import (
+	crip "crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/yunginnanet/common/pool"
+)
+
+// bufSize is how much is read from crypto/rand at a time. Reads this size or larger skip the buffer.
+const bufSize = 512
+
+type buffered struct {
+	buf [bufSize]byte
+	off int
+}
+
+var bufs = &sync.Pool{
+	New: func() interface{} {
+		return &buffered{off: bufSize}
+	},
+}
+
+func mustRead(p []byte) {
+	if _, err := io.ReadFull(crip.Reader, p); err != nil {
+		panic("entropy/secure: crypto/rand failed: " + err.Error())
+	}
+}
+
+// fill copies buffered random bytes into p, wiping them from the buffer as they are handed out.
+func (b *buffered) fill(p []byte) {
+	for len(p) > 0 {
+		if b.off == bufSize {
+			mustRead(b.buf[:])
+			b.off = 0
+		}
+		n := copy(p, b.buf[b.off:])
+		clear(b.buf[b.off : b.off+n])
+		b.off += n
+		p = p[n:]
+	}
+}
+
+func (b *buffered) uint64() uint64 {
+	var v [8]byte
+	b.fill(v[:])
+	return binary.LittleEndian.Uint64(v[:])
+}
+
+// uint64n returns a uniform random number in [0, n) using Lemire's multiply-and-reject method.
+func (b *buffered) uint64n(n uint64) uint64 {
+	hi, lo := bits.Mul64(b.uint64(), n)
+	if lo < n {
+		thresh := -n % n
+		for lo < thresh {
+			hi, lo = bits.Mul64(b.uint64(), n)
+		}
+	}
+	return hi
+}
+
+type reader struct{}
+
+func (reader) Read(p []byte) (int, error) {
+	if len(p) >= bufSize {
+		mustRead(p)
+		return len(p), nil
+	}
+	b := bufs.Get().(*buffered)
+	b.fill(p)
+	bufs.Put(b)
+	return len(p), nil
+}
+
+// Reader is a buffered [io.Reader] over crypto/rand. Its Read never returns an error.
+var Reader io.Reader = reader{}
+
+// Read fills p with random bytes from crypto/rand. It never returns an error.
+func Read(p []byte) (int, error) {
+	return Reader.Read(p)
+}
+
+// Uint64 returns a random uint64 from crypto/rand.
+func Uint64() uint64 {
+	b := bufs.Get().(*buffered)
+	v := b.uint64()
+	bufs.Put(b)
+	return v
+}
+
+// RNGUint32 returns a random uint32 from crypto/rand.
+func RNGUint32() uint32 {
+	return uint32(Uint64() >> 32)
+}
+
+// Intn returns a uniform random int in [0, n) from crypto/rand, without modulo bias.
+// It panics if n <= 0.
+func Intn(n int) int {
+	if n <= 0 {
+		panic("entropy/secure: invalid argument to Intn")
+	}
+	b := bufs.Get().(*buffered)
+	i := int(b.uint64n(uint64(n)))
+	bufs.Put(b)
+	return i
+}
+
+// RNG returns a random int with a maximum of n (exclusive), the secure counterpart of entropy.RNG.
+// It panics if n <= 0.
+func RNG(n int) int {
+	return Intn(n)
+}
+
+// OneInA returns true with a probability of one in million.
+func OneInA(million int) bool {
+	if million == 1 {
+		return true
+	}
+	return Intn(million) == 1
+}
+
+// RandSleepMS sleeps for a random period of time with a maximum of n milliseconds.
+func RandSleepMS(n int) {
+	time.Sleep(time.Duration(Intn(n)) * time.Millisecond)
+}
+
+// RandomStrChoice returns a random item from an input slice of strings.
+func RandomStrChoice(choice []string) string {
+	if len(choice) == 0 {
+		return ""
+	}
+	return choice[Intn(len(choice))]
+}
+
+// characters used for the generation of random strings, the same as in entropy.
+const (
+	charset          = "abcdefghijklmnopqrstuvwxyz1234567890"
+	charsetWithUpper = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz1234567890"
+)
+
+var strBufs = pool.NewBufferFactory()
+
+// RandStr generates a random alphanumeric string of the given size from crypto/rand.
+// Alpha charset used is a-z all lowercase.
+func RandStr(size int) string {
+	return randStr(charset, size)
+}
+
+// RandStrWithUpper generates a random alphanumeric string of the given size from crypto/rand.
+// Alpha charset used is a-Z mixed case.
+func RandStrWithUpper(size int) string {
+	return randStr(charsetWithUpper, size)
+}
+
+// randStr picks each character from a single random byte, rejecting bytes past the largest multiple
+// of the charset length so that every character is equally likely.
+func randStr(set string, size int) string {
+	limit := byte(256 / len(set) * len(set))
+	buf := strBufs.Get()
+	b := bufs.Get().(*buffered)
+	var c [1]byte
+	for i := 0; i < size; {
+		b.fill(c[:])
+		if c[0] >= limit {
+			continue
+		}
+		_ = buf.WriteByte(set[int(c[0])%len(set)])
+		i++
+	}
+	bufs.Put(b)
+	s := buf.String()
+	strBufs.MustPut(buf)
+	return s
+}
@@ -0,0 +1,161 @@
+package secure
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yunginnanet/common/entropy"
+)
+
+func Test_Read(t *testing.T) {
+	t.Parallel()
+	for _, size := range []int{1, 7, 8, 511, 512, 4096} {
+		one, two := make([]byte, size), make([]byte, size)
+		if n, err := Read(one); n != size || err != nil {
+			t.Fatalf("Read(%d) returned %d, %v", size, n, err)
+		}
+		_, _ = Reader.Read(two)
+		if size >= 8 && bytes.Equal(one, two) {
+			t.Errorf("Read(%d) returned the same bytes twice!", size)
+		}
+	}
+	if Uint64() == Uint64() {
+		t.Errorf("Uint64 returned the same value twice!")
+	}
+	if RNGUint32() == RNGUint32() && RNGUint32() == RNGUint32() {
+		t.Errorf("RNGUint32 returned the same value twice, twice!")
+	}
+}
+
+func Test_Intn(t *testing.T) {
+	t.Parallel()
+	for _, n := range []int{1, 2, 3, 7, 36, 1000, math.MaxInt32, math.MaxInt} {
+		for i := 0; i < 1000; i++ {
+			if v := Intn(n); v < 0 || v >= n {
+				t.Fatalf("Intn(%d) returned %d", n, v)
+			}
+		}
+	}
+	if RNG(1) != 0 {
+		t.Errorf("RNG(1) should always be 0")
+	}
+	for n := 0; n < 100; n++ {
+		if !OneInA(1) {
+			t.Fatalf("OneInA failed to trigger when provided '1' as an argument")
+		}
+	}
+	RandSleepMS(5)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Intn(0) should panic")
+		}
+	}()
+	Intn(0)
+}
+
+// chiSquaredLimit approximates the 99.99th percentile of the chi-squared distribution with df degrees
+// of freedom (Wilson-Hilferty), so that a uniform generator fails a single check about once in 10,000 runs.
+func chiSquaredLimit(df int) float64 {
+	const z = 3.719
+	k := float64(df)
+	return k * math.Pow(1-2/(9*k)+z*math.Sqrt(2/(9*k)), 3)
+}
+
+// chiSquared returns the chi-squared statistic of counts against a uniform distribution.
+func chiSquared(counts []int, total int) float64 {
+	expected := float64(total) / float64(len(counts))
+	var chi float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi += d * d / expected
+	}
+	return chi
+}
+
+func Test_Uniformity(t *testing.T) {
+	t.Parallel()
+	const draws = 360000
+	for _, set := range []string{charset, charsetWithUpper} {
+		counts := make([]int, len(set))
+		gen := RandStr
+		if len(set) == len(charsetWithUpper) {
+			gen = RandStrWithUpper
+		}
+		for _, c := range gen(draws) {
+			counts[strings.IndexRune(set, c)]++
+		}
+		if chi, limit := chiSquared(counts, draws), chiSquaredLimit(len(set)-1); chi > limit {
+			t.Errorf("%d character set is not uniform: chi-squared %.2f > %.2f", len(set), chi, limit)
+		}
+	}
+
+	counts := make([]int, 3)
+	choices := []string{"a", "b", "c"}
+	for i := 0; i < draws; i++ {
+		counts[strings.Index("abc", RandomStrChoice(choices))]++
+	}
+	if chi, limit := chiSquared(counts, draws), chiSquaredLimit(len(counts)-1); chi > limit {
+		t.Errorf("RandomStrChoice is not uniform: chi-squared %.2f > %.2f", chi, limit)
+	}
+	if RandomStrChoice(nil) != "" {
+		t.Errorf("RandomStrChoice(nil) should be empty")
+	}
+}
+
+func Test_RandStr(t *testing.T) {
+	t.Parallel()
+	seen := make(map[string]struct{})
+	mu := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 500; n++ {
+				s := RandStr(55)
+				if len(s) != 55 || len(RandStrWithUpper(15)) != 15 {
+					t.Errorf("RandStr output length inconsistency")
+				}
+				mu.Lock()
+				if _, dup := seen[s]; dup {
+					t.Errorf("hit a duplicate! %s", s)
+				}
+				seen[s] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func Benchmark_RandStr(b *testing.B) {
+	b.Run("entropy", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			entropy.RandStr(55)
+		}
+	})
+	b.Run("secure", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			RandStr(55)
+		}
+	})
+}
+
+func Benchmark_Intn(b *testing.B) {
+	b.Run("entropy", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			entropy.RNG(1000)
+		}
+	})
+	b.Run("secure", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			Intn(1000)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package entropy
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"nullprogram.com/x/rng"
+)
+
+// Source is a SplitMix64 generator built from an explicit seed, exposing the same helpers as the package.
+// Two Sources created with the same seed produce the same sequence of values, which makes fuzzing,
+// simulations and test fixtures replayable.
+//
+// A Source is safe for concurrent use, but its output is only reproducible when the order of calls is.
+//
+// Go doesn't allow type parameters on methods, so the generic helpers take a Source as an argument instead,
+// see [ChoiceFrom], [ShuffleFrom], [SampleFrom] and [WeightedChoice.PickFrom].
+type Source struct {
+	mu   sync.Mutex
+	r    *rand.Rand
+	seed int64
+}
+
+func newSplitMix(seed int64) *rand.Rand {
+	sm64 := new(rng.SplitMix64)
+	sm64.Seed(seed)
+	return rand.New(sm64) //nolint:gosec
+}
+
+// NewSource returns a new [Source] seeded with seed.
+func NewSource(seed int64) *Source {
+	return &Source{r: newSplitMix(seed), seed: seed}
+}
+
+// Seed returns the seed the Source was created with.
+func (s *Source) Seed() int64 {
+	return s.seed
+}
+
+// derive returns a new generator seeded from the next value of s, marked so it is never pooled.
+func (s *Source) derive() *rand.Rand {
+	s.mu.Lock()
+	seed := s.r.Int63()
+	s.mu.Unlock()
+	r := newSplitMix(seed)
+	markDerived(r)
+	return r
+}
+
+// derived holds the addresses of generators handed out by derive, so that randPool.Put can refuse them.
+// Addresses rather than pointers are kept so that the set doesn't keep generators alive, and a finalizer
+// removes each one before its memory can be reused.
+var derived = struct {
+	sync.Mutex
+	live  atomic.Int64
+	addrs map[uintptr]struct{}
+}{addrs: make(map[uintptr]struct{})}
+
+func markDerived(r *rand.Rand) {
+	addr := uintptr(unsafe.Pointer(r))
+	derived.Lock()
+	derived.addrs[addr] = struct{}{}
+	derived.live.Add(1)
+	derived.Unlock()
+	runtime.SetFinalizer(r, func(*rand.Rand) {
+		derived.Lock()
+		delete(derived.addrs, addr)
+		derived.live.Add(-1)
+		derived.Unlock()
+	})
+}
+
+func isDerived(r *rand.Rand) bool {
+	// the fast path, nothing has been derived or everything derived has been collected.
+	if derived.live.Load() == 0 {
+		return false
+	}
+	derived.Lock()
+	_, ok := derived.addrs[uintptr(unsafe.Pointer(r))]
+	derived.Unlock()
+	return ok
+}
+
+// Uint64 returns a random uint64.
+func (s *Source) Uint64() uint64 {
+	s.mu.Lock()
+	v := s.r.Uint64()
+	s.mu.Unlock()
+	return v
+}
+
+// RNGUint32 returns a random uint32.
+func (s *Source) RNGUint32() uint32 {
+	s.mu.Lock()
+	v := s.r.Uint32()
+	s.mu.Unlock()
+	return v
+}
+
+// Uint64n returns a uniform random uint64 in [0, n) without modulo bias. It panics if n == 0.
+func (s *Source) Uint64n(n uint64) uint64 {
+	if n == 0 {
+		panic("entropy: invalid argument to Uint64n")
+	}
+	s.mu.Lock()
+	v := uint64n(s.r, n)
+	s.mu.Unlock()
+	return v
+}
+
+// Int63n returns a uniform random int64 in [0, n) without modulo bias. It panics if n <= 0.
+func (s *Source) Int63n(n int64) int64 {
+	if n <= 0 {
+		panic("entropy: invalid argument to Int63n")
+	}
+	return int64(s.Uint64n(uint64(n)))
+}
+
+// IntRange returns a uniform random int in [min, max) without modulo bias. It panics if max <= min.
+func (s *Source) IntRange(min, max int) int {
+	if max <= min {
+		panic("entropy: invalid argument to IntRange")
+	}
+	return min + int(s.Uint64n(uint64(max)-uint64(min)))
+}
+
+// RNG returns a random int with a maximum of n (exclusive). It panics if n <= 0.
+func (s *Source) RNG(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.Intn(n)
+}
+
+// OneInA returns true with a probability of one in million.
+func (s *Source) OneInA(million int) bool {
+	if million == 1 {
+		return true
+	}
+	return s.RNG(million) == 1
+}
+
+// RandSleepMS sleeps for a random period of time with a maximum of n milliseconds.
+func (s *Source) RandSleepMS(n int) {
+	time.Sleep(time.Duration(s.RNG(n)) * time.Millisecond)
+}
+
+// RandomStrChoice returns a random item from an input slice of strings.
+func (s *Source) RandomStrChoice(choice []string) string {
+	return ChoiceFrom(s, choice)
+}
+
+// RandStr generates a random alphanumeric string of the given size. Alpha charset used is a-z all lowercase.
+func (s *Source) RandStr(size int) string {
+	s.mu.Lock()
+	str := randStrFrom(s.r, charset, size)
+	s.mu.Unlock()
+	return str
+}
+
+// RandStrWithUpper generates a random alphanumeric string of the given size. Alpha charset used is a-Z mixed case.
+func (s *Source) RandStrWithUpper(size int) string {
+	s.mu.Lock()
+	str := randStrFrom(s.r, charsetWithUpper, size)
+	s.mu.Unlock()
+	return str
+}
+
+// RandStrFrom generates a random string of n characters picked uniformly from alphabet, see [RandStrFrom].
+func (s *Source) RandStrFrom(alphabet string, n int) string {
+	if len(alphabet) == 0 && n > 0 {
+		panic("entropy: empty alphabet")
+	}
+	s.mu.Lock()
+	str := randStrFrom(s.r, alphabet, n)
+	s.mu.Unlock()
+	return str
+}
+
+// RandFormat generates a random string following template, see [RandFormat].
+func (s *Source) RandFormat(template string) string {
+	s.mu.Lock()
+	str := randFormat(s.r, template)
+	s.mu.Unlock()
+	return str
+}
+
+// RandPronounceable generates a random string of n letters alternating between consonants and vowels.
+func (s *Source) RandPronounceable(n int) string {
+	s.mu.Lock()
+	str := randPronounceable(s.r, n)
+	s.mu.Unlock()
+	return str
+}
+
+// ChoiceFrom is like [Choice], but draws from src.
+func ChoiceFrom[T any](src *Source, s []T) T {
+	src.mu.Lock()
+	v := choice(src.r, s)
+	src.mu.Unlock()
+	return v
+}
+
+// ShuffleFrom is like [Shuffle], but draws from src.
+func ShuffleFrom[T any](src *Source, s []T) {
+	src.mu.Lock()
+	shuffle(src.r, s)
+	src.mu.Unlock()
+}
+
+// SampleFrom is like [Sample], but draws from src.
+func SampleFrom[T any](src *Source, s []T, k int) []T {
+	src.mu.Lock()
+	picked := sample(src.r, s, k)
+	src.mu.Unlock()
+	return picked
+}
+
+// pinned, when set, replaces crypto/rand seeding for the package-level helpers.
+var pinned atomic.Pointer[Source]
+
+/*
+PinSeed makes every package-level helper (RandStr, RNG, Choice, AcquireRand, GetOptimizedRand, etc.)
+draw from generators derived from seed instead of crypto/rand, and returns a function that undoes it.
+It is meant for tests: log the seed of every run and a failing one can be replayed exactly.
+
+	seed := entropy.GetCryptoSeed()
+	t.Logf("entropy seed: %d", seed)
+	t.Cleanup(entropy.PinSeed(seed))
+
+Output is only reproducible when helpers are called in the same order, so avoid pinning from parallel
+tests. While pinned, each helper call allocates a fresh generator instead of using the pool.
+*/
+func PinSeed(seed int64) (restore func()) {
+	prev := pinned.Swap(NewSource(seed))
+	return func() {
+		pinned.Store(prev)
+	}
+}
+
+// PinnedSeed returns the seed set by [PinSeed], and whether the package-level helpers are currently pinned.
+func PinnedSeed() (int64, bool) {
+	if src := pinned.Load(); src != nil {
+		return src.seed, true
+	}
+	return 0, false
+}
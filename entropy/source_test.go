@@ -0,0 +1,174 @@
+package entropy
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// sequence exercises every Source helper and records the output.
+func sequence(src *Source) []string {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	shuffled := append([]int(nil), items...)
+	ShuffleFrom(src, shuffled)
+	wc, _ := NewWeightedChoice([]string{"x", "y", "z"}, []float64{1, 2, 3})
+	return []string{
+		fmt.Sprint(src.Uint64()),
+		fmt.Sprint(src.RNGUint32()),
+		fmt.Sprint(src.Uint64n(1000)),
+		fmt.Sprint(src.Int63n(1000)),
+		fmt.Sprint(src.IntRange(-50, 50)),
+		fmt.Sprint(src.RNG(1000)),
+		fmt.Sprint(src.OneInA(2)),
+		src.RandStr(20),
+		src.RandStrWithUpper(20),
+		src.RandomStrChoice([]string{"a", "b", "c", "d"}),
+		src.RandStrFrom(AlphabetBase58, 20),
+		src.RandFormat("XXXX-9999"),
+		src.RandPronounceable(8),
+		fmt.Sprint(ChoiceFrom(src, items)),
+		fmt.Sprint(shuffled),
+		fmt.Sprint(SampleFrom(src, items, 3)),
+		wc.PickFrom(src),
+	}
+}
+
+func Test_Source(t *testing.T) {
+	t.Parallel()
+	one, two := NewSource(1337), NewSource(1337)
+	if one.Seed() != 1337 {
+		t.Errorf("[FAIL] Seed() = %d, expected 1337", one.Seed())
+	}
+	for round := 0; round < 50; round++ {
+		a, b := sequence(one), sequence(two)
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("[FAIL] round %d: sources with the same seed diverged at %d: %s != %s", round, i, a[i], b[i])
+			}
+		}
+	}
+
+	a, b := NewSource(1).RandStr(32), NewSource(2).RandStr(32)
+	if a == b {
+		t.Errorf("[FAIL] sources with different seeds produced the same string: %s", a)
+	}
+
+	// for coverage, and for the race detector
+	src := NewSource(GetCryptoSeed())
+	wg := &sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				sequence(src)
+			}
+		}()
+	}
+	wg.Wait()
+	src.RandSleepMS(2)
+}
+
+// pinnedRun records the output of the package-level helpers.
+func pinnedRun() []string {
+	r := AcquireRand()
+	acquired := r.Int63()
+	ReleaseRand(r)
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	Shuffle(items)
+	return []string{
+		RandStr(16),
+		RandStrWithUpper(16),
+		fmt.Sprint(RNG(1 << 30)),
+		fmt.Sprint(RNGUint32()),
+		fmt.Sprint(Uint64n(1 << 40)),
+		fmt.Sprint(IntRange(-1000, 1000)),
+		fmt.Sprint(Choice(items)),
+		fmt.Sprint(items),
+		fmt.Sprint(Sample(items, 4)),
+		fmt.Sprint(acquired),
+		fmt.Sprint(GetOptimizedRand().Int63()),
+	}
+}
+
+// Test_PinSeed deliberately doesn't call t.Parallel, so that no other test draws from the pinned generators.
+func Test_PinSeed(t *testing.T) {
+	if _, ok := PinnedSeed(); ok {
+		t.Fatalf("[FAIL] helpers should not start out pinned")
+	}
+
+	restore := PinSeed(42)
+	if seed, ok := PinnedSeed(); !ok || seed != 42 {
+		t.Errorf("[FAIL] PinnedSeed() = %d, %t; expected 42, true", seed, ok)
+	}
+	first := pinnedRun()
+	restore()
+
+	if _, ok := PinnedSeed(); ok {
+		t.Fatalf("[FAIL] restore should unpin the helpers")
+	}
+	unpinned := pinnedRun()
+
+	restore = PinSeed(42)
+	second := pinnedRun()
+	restore()
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("[FAIL] pinned run diverged at %d: %s != %s", i, first[i], second[i])
+		}
+	}
+	if first[0] == unpinned[0] {
+		t.Errorf("[FAIL] unpinned helpers returned the pinned output: %s", first[0])
+	}
+
+	// nested pins restore the outer one
+	outer := PinSeed(1)
+	inner := PinSeed(2)
+	inner()
+	if seed, _ := PinnedSeed(); seed != 1 {
+		t.Errorf("[FAIL] restoring a nested pin should restore the outer seed, got %d", seed)
+	}
+	outer()
+}
+
+// Test_PinSeedPool doesn't call t.Parallel for the same reason as Test_PinSeed.
+func Test_PinSeedPool(t *testing.T) {
+	unpinned := AcquireRand()
+	restore := PinSeed(42)
+	pinnedRand := AcquireRand()
+	// returned while still pinned, a crypto seeded generator belongs back in the pool
+	ReleaseRand(unpinned)
+	restore()
+	// returned after unpinning, a pinned generator must still never be pooled
+	ReleaseRand(pinnedRand)
+
+	if isDerived(unpinned) {
+		t.Errorf("[FAIL] a generator from the pool was marked as derived")
+	}
+	if !isDerived(pinnedRand) {
+		t.Fatalf("[FAIL] a generator handed out while pinned wasn't marked as derived")
+	}
+	for i := 0; i < 100; i++ {
+		r := lolXD.Get()
+		if r == pinnedRand {
+			t.Fatalf("[FAIL] a pinned generator made it into the pool")
+		}
+		defer lolXD.Put(r)
+	}
+}
+
+func Benchmark_Source(b *testing.B) {
+	src := NewSource(GetCryptoSeed())
+	b.Run("RandStr", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			src.RandStr(55)
+		}
+	})
+	b.Run("RNG", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			src.RNG(1000)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package entropy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrULIDOverflow is returned by [MonotonicULID.Next] when the random part of a ULID can't be incremented
+// any further within the same millisecond.
+var ErrULIDOverflow = errors.New("entropy: ULID overflow")
+
+// ULID is a Universally Unique Lexicographically Sortable Identifier: a 48 bit millisecond timestamp followed by
+// 80 random bits, encoded as 26 characters of Crockford's base32. The zero value is the zero ULID.
+type ULID [16]byte
+
+// NewULID returns a ULID for the current time, with its random bits from the pooled generators.
+// Use [NewSecureULID] for ULIDs that must not be guessed, or [MonotonicULID] for strict ordering.
+func NewULID() ULID {
+	return newULID(time.Now(), false)
+}
+
+// NewSecureULID is like [NewULID], but its random bits come from crypto/rand.
+func NewSecureULID() ULID {
+	return newULID(time.Now(), true)
+}
+
+func newULID(now time.Time, secure bool) (u ULID) {
+	u.setTime(uint64(now.UnixMilli()))
+	fillRandom(u[6:], secure)
+	return u
+}
+
+func (u *ULID) setTime(ms uint64) {
+	u[0], u[1], u[2], u[3], u[4], u[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+}
+
+// MonotonicULID generates ULIDs that sort in the order they were generated, even within the same millisecond.
+// Within a millisecond the random part of the previous ULID is incremented, as described by the ULID spec.
+// It is safe for concurrent use.
+type MonotonicULID struct {
+	mu     sync.Mutex
+	secure bool
+	last   ULID
+}
+
+// NewMonotonicULID returns a new [MonotonicULID]. The random bits come from crypto/rand if secure is true,
+// or from the pooled generators otherwise.
+func NewMonotonicULID(secure bool) *MonotonicULID {
+	return &MonotonicULID{secure: secure}
+}
+
+// Next returns the next ULID. It fails with [ErrULIDOverflow] in the (astronomically unlikely) case of
+// the random part overflowing within a millisecond.
+func (m *MonotonicULID) Next() (ULID, error) {
+	return m.next(time.Now())
+}
+
+func (m *MonotonicULID) next(now time.Time) (ULID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// a clock going backwards is treated as the same millisecond, so ordering is kept.
+	if ms := uint64(now.UnixMilli()); ms > m.last.Timestamp() {
+		m.last = newULID(now, m.secure)
+		return m.last, nil
+	}
+	next := m.last
+	for i := len(next) - 1; i >= 6; i-- {
+		next[i]++
+		if next[i] != 0 {
+			m.last = next
+			return next, nil
+		}
+	}
+	return ULID{}, ErrULIDOverflow
+}
+
+// crockfordDec maps the characters of AlphabetCrockford, in either case, to their values. Anything else is 0xff.
+var crockfordDec = func() (dec [256]byte) {
+	for i := range dec {
+		dec[i] = 0xff
+	}
+	for i := 0; i < len(AlphabetCrockford); i++ {
+		c := AlphabetCrockford[i]
+		dec[c] = byte(i)
+		if c >= 'A' && c <= 'Z' {
+			dec[c+'a'-'A'] = byte(i)
+		}
+	}
+	return dec
+}()
+
+// ParseULID parses the 26 character form of a ULID. Either case is accepted.
+func ParseULID(s string) (u ULID, err error) {
+	if len(s) != 26 {
+		return u, fmt.Errorf("%w: ULID must be 26 characters, got %d", ErrInvalidID, len(s))
+	}
+	// 26 characters hold 130 bits, so the first one can't be above 7 without overflowing 128.
+	if crockfordDec[s[0]] > 7 {
+		return u, fmt.Errorf("%w: malformed ULID %q", ErrInvalidID, s)
+	}
+	var hi, lo uint64
+	for i := 0; i < len(s); i++ {
+		v := crockfordDec[s[i]]
+		if v == 0xff {
+			return ULID{}, fmt.Errorf("%w: malformed ULID %q", ErrInvalidID, s)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	for i := 0; i < 8; i++ {
+		u[i], u[8+i] = byte(hi>>(56-8*i)), byte(lo>>(56-8*i))
+	}
+	return u, nil
+}
+
+// MustParseULID is like [ParseULID] but panics if s can't be parsed.
+func MustParseULID(s string) ULID {
+	u, err := ParseULID(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// String returns the 26 character, uppercase form of u.
+func (u ULID) String() string {
+	var buf [26]byte
+	u.encode(buf[:])
+	return string(buf[:])
+}
+
+func (u ULID) encode(dst []byte) {
+	var hi, lo uint64
+	for i := 0; i < 8; i++ {
+		hi, lo = hi<<8|uint64(u[i]), lo<<8|uint64(u[8+i])
+	}
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = AlphabetCrockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+}
+
+// Timestamp returns the millisecond Unix timestamp of u.
+func (u ULID) Timestamp() uint64 {
+	return uint64(u[0])<<40 | uint64(u[1])<<32 | uint64(u[2])<<24 | uint64(u[3])<<16 | uint64(u[4])<<8 | uint64(u[5])
+}
+
+// Time returns the creation time of u, with millisecond precision.
+func (u ULID) Time() time.Time {
+	return time.UnixMilli(int64(u.Timestamp()))
+}
+
+// IsZero reports whether u is the zero ULID.
+func (u ULID) IsZero() bool {
+	return u == ULID{}
+}
+
+// Compare returns -1, 0 or +1 depending on whether u sorts before, the same as, or after other.
+func (u ULID) Compare(other ULID) int {
+	return bytes.Compare(u[:], other[:])
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (u ULID) MarshalText() ([]byte, error) {
+	buf := make([]byte, 26)
+	u.encode(buf)
+	return buf, nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (u *ULID) UnmarshalText(text []byte) error {
+	parsed, err := ParseULID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+func (u ULID) MarshalBinary() ([]byte, error) {
+	return u[:], nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (u *ULID) UnmarshalBinary(data []byte) error {
+	if len(data) != len(u) {
+		return fmt.Errorf("%w: ULID must be %d bytes, got %d", ErrInvalidID, len(u), len(data))
+	}
+	copy(u[:], data)
+	return nil
+}
@@ -0,0 +1,184 @@
+package entropy
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_ULID(t *testing.T) {
+	t.Parallel()
+	// the example from the ULID spec
+	u := MustParseULID("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	if u.Timestamp() != 1469922850259 {
+		t.Errorf("[FAIL] expected a timestamp of 1469922850259, got %d", u.Timestamp())
+	}
+	if u.String() != "01ARZ3NDEKTSV4RRFFQ69G5FAV" {
+		t.Errorf("[FAIL] round trip of the spec example returned %s", u)
+	}
+	if lower := MustParseULID("01arz3ndektsv4rrffq69g5fav"); lower != u {
+		t.Errorf("[FAIL] lowercase ULIDs should parse the same")
+	}
+
+	max := ULID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if max.String() != "7ZZZZZZZZZZZZZZZZZZZZZZZZZ" || MustParseULID(max.String()) != max {
+		t.Errorf("[FAIL] the largest ULID encodes as %s", max)
+	}
+	var zero ULID
+	if !zero.IsZero() || zero.String() != "00000000000000000000000000" {
+		t.Errorf("[FAIL] the zero ULID encodes as %s", zero)
+	}
+
+	before := time.Now()
+	seen := make(map[ULID]struct{})
+	for _, gen := range []func() ULID{NewULID, NewSecureULID} {
+		for i := 0; i < 10000; i++ {
+			id := gen()
+			if d := id.Time().Sub(before); d < -time.Millisecond || d > time.Minute {
+				t.Fatalf("[FAIL] ULID time is off by %s", d)
+			}
+			if _, dup := seen[id]; dup {
+				t.Fatalf("[FAIL] hit a duplicate! %s", id)
+			}
+			seen[id] = struct{}{}
+			if parsed := MustParseULID(id.String()); parsed != id {
+				t.Fatalf("[FAIL] round trip of %s returned %s", id, parsed)
+			}
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"01ARZ3NDEKTSV4RRFFQ69G5FA",
+		"81ARZ3NDEKTSV4RRFFQ69G5FAV",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAU",
+		"01ARZ3NDEKTSV4RRFFQ69G5FA!",
+	} {
+		if _, err := ParseULID(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("[FAIL] ParseULID(%q): expected ErrInvalidID, got %v", s, err)
+		}
+	}
+}
+
+func Test_MonotonicULID(t *testing.T) {
+	t.Parallel()
+	for _, secure := range []bool{false, true} {
+		m := NewMonotonicULID(secure)
+		now := time.Now()
+		prev, err := m.next(now)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		for i := 0; i < 1000; i++ {
+			// the same millisecond, then a clock going backwards, must both keep incrementing
+			at := now
+			if i%3 == 0 {
+				at = now.Add(-time.Second)
+			}
+			id, err := m.next(at)
+			if err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			if id.Compare(prev) <= 0 || id.Timestamp() != prev.Timestamp() {
+				t.Fatalf("[FAIL] monotonic ULIDs out of order: %s after %s", id, prev)
+			}
+			if id.String() <= prev.String() {
+				t.Fatalf("[FAIL] monotonic ULID strings out of order: %s after %s", id, prev)
+			}
+			prev = id
+		}
+		later, _ := m.next(now.Add(time.Millisecond))
+		if later.Timestamp() != prev.Timestamp()+1 {
+			t.Errorf("[FAIL] a new millisecond should start a new ULID")
+		}
+	}
+
+	m := NewMonotonicULID(false)
+	now := time.Now()
+	m.last.setTime(uint64(now.UnixMilli()))
+	for i := 6; i < len(m.last); i++ {
+		m.last[i] = 0xff
+	}
+	if _, err := m.next(now); !errors.Is(err, ErrULIDOverflow) {
+		t.Errorf("[FAIL] expected ErrULIDOverflow, got %v", err)
+	}
+
+	m = NewMonotonicULID(false)
+	ids := make(chan ULID, 8*500)
+	wg := &sync.WaitGroup{}
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 500; n++ {
+				id, err := m.Next()
+				if err != nil {
+					t.Errorf("[FAIL] %s", err.Error())
+					return
+				}
+				ids <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := make(map[ULID]struct{})
+	for id := range ids {
+		if _, dup := seen[id]; dup {
+			t.Fatalf("[FAIL] hit a duplicate! %s", id)
+		}
+		seen[id] = struct{}{}
+	}
+}
+
+func Test_ULIDMarshal(t *testing.T) {
+	t.Parallel()
+	in := map[string]ULID{"id": NewULID()}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if !strings.Contains(string(data), in["id"].String()) {
+		t.Errorf("[FAIL] ULID wasn't marshaled as text: %s", data)
+	}
+	var out map[string]ULID
+	if err = json.Unmarshal(data, &out); err != nil || out["id"] != in["id"] {
+		t.Errorf("[FAIL] JSON round trip failed: %v", err)
+	}
+
+	bin, _ := in["id"].MarshalBinary()
+	var u ULID
+	if err = u.UnmarshalBinary(bin); err != nil || u != in["id"] {
+		t.Errorf("[FAIL] binary round trip failed: %v", err)
+	}
+	if err = u.UnmarshalBinary(append(bin, 0)); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("[FAIL] expected ErrInvalidID, got %v", err)
+	}
+	if err = u.UnmarshalText([]byte("bogus")); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("[FAIL] expected ErrInvalidID, got %v", err)
+	}
+}
+
+func Benchmark_ULID(b *testing.B) {
+	b.Run("New", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			NewULID()
+		}
+	})
+	b.Run("Monotonic", func(b *testing.B) {
+		m := NewMonotonicULID(false)
+		for n := 0; n < b.N; n++ {
+			_, _ = m.Next()
+		}
+	})
+	b.Run("String", func(b *testing.B) {
+		u := NewULID()
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_ = u.String()
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package entropy
+
+import (
+	"bytes"
+	crip "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidID is returned when parsing or unmarshaling a [UUID], [ULID] or [KSUID] fails.
+var ErrInvalidID = errors.New("entropy: invalid ID")
+
+// fillRandom fills p from the pooled generators, or from crypto/rand when secure is true.
+func fillRandom(p []byte, secure bool) {
+	if secure {
+		if _, err := io.ReadFull(crip.Reader, p); err != nil {
+			panic("entropy: crypto/rand failed: " + err.Error())
+		}
+		return
+	}
+	r := lolXD.Get()
+	var word [8]byte
+	for len(p) > 0 {
+		binary.LittleEndian.PutUint64(word[:], r.Uint64())
+		p = p[copy(p, word[:]):]
+	}
+	lolXD.Put(r)
+}
+
+// UUID is an RFC 9562 UUID. The zero value is the nil UUID.
+type UUID [16]byte
+
+// NewUUIDv4 returns a random (version 4) UUID from the pooled generators.
+// Use [NewSecureUUIDv4] for UUIDs that must not be guessed.
+func NewUUIDv4() UUID {
+	return newUUIDv4(false)
+}
+
+// NewSecureUUIDv4 returns a random (version 4) UUID from crypto/rand.
+func NewSecureUUIDv4() UUID {
+	return newUUIDv4(true)
+}
+
+func newUUIDv4(secure bool) (u UUID) {
+	fillRandom(u[:], secure)
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+var (
+	v7Mu   sync.Mutex
+	lastV7 uint64
+)
+
+// NewUUIDv7 returns a time-ordered (version 7) UUID, with its random bits from the pooled generators.
+// The 12 bits after the millisecond timestamp hold sub-millisecond precision, and UUIDs from the same
+// process always sort in the order they were created.
+func NewUUIDv7() UUID {
+	return newUUIDv7(time.Now(), false)
+}
+
+// NewSecureUUIDv7 is like [NewUUIDv7], but its random bits come from crypto/rand.
+func NewSecureUUIDv7() UUID {
+	return newUUIDv7(time.Now(), true)
+}
+
+func newUUIDv7(now time.Time, secure bool) (u UUID) {
+	// milliseconds, then the fraction of the millisecond scaled to 12 bits.
+	v := uint64(now.UnixMilli())<<12 | uint64(now.Nanosecond()%1e6)*4096/1e6
+	v7Mu.Lock()
+	if v <= lastV7 {
+		v = lastV7 + 1
+	}
+	lastV7 = v
+	v7Mu.Unlock()
+
+	fillRandom(u[8:], secure)
+	ms := v >> 12
+	u[0], u[1], u[2], u[3], u[4], u[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	u[6] = 0x70 | byte(v>>8)&0x0f
+	u[7] = byte(v)
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// ParseUUID parses the canonical form of a UUID, e.g. "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+// Either case is accepted, as are the urn:uuid: prefix, surrounding braces and the 32 character form without hyphens.
+func ParseUUID(s string) (u UUID, err error) {
+	raw := s
+	switch {
+	case len(s) == 45 && strings.EqualFold(s[:9], "urn:uuid:"):
+		s = s[9:]
+	case len(s) == 38 && s[0] == '{' && s[37] == '}':
+		s = s[1:37]
+	}
+	switch len(s) {
+	case 32:
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, fmt.Errorf("%w: malformed UUID %q", ErrInvalidID, raw)
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	default:
+		return u, fmt.Errorf("%w: malformed UUID %q", ErrInvalidID, raw)
+	}
+	if _, err = hex.Decode(u[:], []byte(s)); err != nil {
+		return UUID{}, fmt.Errorf("%w: malformed UUID %q", ErrInvalidID, raw)
+	}
+	return u, nil
+}
+
+// MustParseUUID is like [ParseUUID] but panics if s can't be parsed.
+func MustParseUUID(s string) UUID {
+	u, err := ParseUUID(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// String returns the canonical, lowercase form of u.
+func (u UUID) String() string {
+	var buf [36]byte
+	u.encode(buf[:])
+	return string(buf[:])
+}
+
+func (u UUID) encode(dst []byte) {
+	hex.Encode(dst[0:8], u[0:4])
+	dst[8] = '-'
+	hex.Encode(dst[9:13], u[4:6])
+	dst[13] = '-'
+	hex.Encode(dst[14:18], u[6:8])
+	dst[18] = '-'
+	hex.Encode(dst[19:23], u[8:10])
+	dst[23] = '-'
+	hex.Encode(dst[24:], u[10:])
+}
+
+// Version returns the version number of u, e.g. 4 or 7.
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// IsZero reports whether u is the nil UUID.
+func (u UUID) IsZero() bool {
+	return u == UUID{}
+}
+
+// Time returns the creation time of a version 7 UUID, or the zero time for any other version.
+func (u UUID) Time() time.Time {
+	if u.Version() != 7 {
+		return time.Time{}
+	}
+	ms := int64(u[0])<<40 | int64(u[1])<<32 | int64(u[2])<<24 | int64(u[3])<<16 | int64(u[4])<<8 | int64(u[5])
+	frac := int64(u[6]&0x0f)<<8 | int64(u[7])
+	return time.UnixMilli(ms).Add(time.Duration(frac * 1e6 / 4096))
+}
+
+// Compare returns -1, 0 or +1 depending on whether u sorts before, the same as, or after other.
+func (u UUID) Compare(other UUID) int {
+	return bytes.Compare(u[:], other[:])
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (u UUID) MarshalText() ([]byte, error) {
+	buf := make([]byte, 36)
+	u.encode(buf)
+	return buf, nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+func (u UUID) MarshalBinary() ([]byte, error) {
+	return u[:], nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (u *UUID) UnmarshalBinary(data []byte) error {
+	if len(data) != len(u) {
+		return fmt.Errorf("%w: UUID must be %d bytes, got %d", ErrInvalidID, len(u), len(data))
+	}
+	copy(u[:], data)
+	return nil
+}
@@ -0,0 +1,178 @@
+package entropy
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_UUIDv4(t *testing.T) {
+	t.Parallel()
+	seen := make(map[UUID]struct{})
+	for _, gen := range []func() UUID{NewUUIDv4, NewSecureUUIDv4} {
+		for i := 0; i < 10000; i++ {
+			u := gen()
+			if u.Version() != 4 || u[8]&0xc0 != 0x80 {
+				t.Fatalf("[FAIL] %s has the wrong version or variant", u)
+			}
+			if _, dup := seen[u]; dup {
+				t.Fatalf("[FAIL] hit a duplicate! %s", u)
+			}
+			seen[u] = struct{}{}
+		}
+	}
+	if !NewUUIDv4().Time().IsZero() {
+		t.Errorf("[FAIL] a version 4 UUID shouldn't have a time")
+	}
+}
+
+func Test_UUIDv7(t *testing.T) {
+	t.Parallel()
+	// the example from RFC 9562, appendix A.6
+	u := MustParseUUID("017F22E2-79B0-7CC3-98C4-DC0C0C07398F")
+	if u.Version() != 7 {
+		t.Errorf("[FAIL] expected version 7, got %d", u.Version())
+	}
+	if got := u.Time().UnixMilli(); got != 1645557742000 {
+		t.Errorf("[FAIL] expected a timestamp of 1645557742000, got %d", got)
+	}
+
+	before := time.Now()
+	ids := make([]UUID, 10000)
+	for i := range ids {
+		if i%2 == 0 {
+			ids[i] = NewUUIDv7()
+		} else {
+			ids[i] = NewSecureUUIDv7()
+		}
+		if ids[i].Version() != 7 || ids[i][8]&0xc0 != 0x80 {
+			t.Fatalf("[FAIL] %s has the wrong version or variant", ids[i])
+		}
+		if i > 0 && ids[i].Compare(ids[i-1]) <= 0 {
+			t.Fatalf("[FAIL] UUIDv7s out of order: %s after %s", ids[i], ids[i-1])
+		}
+	}
+	if d := ids[0].Time().Sub(before); d < -time.Millisecond || d > time.Second {
+		t.Errorf("[FAIL] UUIDv7 time is off by %s", d)
+	}
+
+	// a clock going backwards must not break ordering
+	a := newUUIDv7(time.Now().Add(time.Hour), false)
+	b := newUUIDv7(time.Now(), false)
+	if b.Compare(a) <= 0 {
+		t.Errorf("[FAIL] UUIDv7 went backwards with the clock: %s after %s", b, a)
+	}
+}
+
+func Test_ParseUUID(t *testing.T) {
+	t.Parallel()
+	const canonical = "919108f7-52d1-4320-9bac-f847db4148a8"
+	for _, s := range []string{
+		canonical,
+		strings.ToUpper(canonical),
+		"urn:uuid:" + canonical,
+		"{" + canonical + "}",
+		strings.ReplaceAll(canonical, "-", ""),
+	} {
+		u, err := ParseUUID(s)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		if u.String() != canonical {
+			t.Errorf("[FAIL] ParseUUID(%q).String() = %s", s, u)
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"919108f7-52d1-4320-9bac-f847db4148a",
+		"919108f7x52d1-4320-9bac-f847db4148a8",
+		"919108f7-52d1-4320-9bac-f847db4148ag",
+		"{919108f7-52d1-4320-9bac-f847db4148a8",
+	} {
+		if _, err := ParseUUID(s); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("[FAIL] ParseUUID(%q): expected ErrInvalidID, got %v", s, err)
+		}
+	}
+
+	var zero UUID
+	if !zero.IsZero() || zero.String() != "00000000-0000-0000-0000-000000000000" || NewUUIDv4().IsZero() {
+		t.Errorf("[FAIL] nil UUID handling is broken")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("[FAIL] MustParseUUID should panic on invalid input")
+		}
+	}()
+	MustParseUUID("nope")
+}
+
+func Test_UUIDMarshal(t *testing.T) {
+	t.Parallel()
+	in := struct {
+		ID  UUID   `json:"id"`
+		IDs []UUID `json:"ids"`
+	}{ID: NewUUIDv7(), IDs: []UUID{NewUUIDv4(), NewUUIDv4()}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if !strings.Contains(string(data), in.ID.String()) {
+		t.Errorf("[FAIL] UUID wasn't marshaled as text: %s", data)
+	}
+	out := in
+	out.ID, out.IDs = UUID{}, nil
+	if err = json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if out.ID != in.ID || len(out.IDs) != 2 || out.IDs[1] != in.IDs[1] {
+		t.Errorf("[FAIL] JSON round trip lost data: %+v != %+v", out, in)
+	}
+	if err = json.Unmarshal([]byte(`{"id":"bogus"}`), &out); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("[FAIL] expected ErrInvalidID, got %v", err)
+	}
+
+	bin, _ := in.ID.MarshalBinary()
+	var u UUID
+	if err = u.UnmarshalBinary(bin); err != nil || u != in.ID {
+		t.Errorf("[FAIL] binary round trip failed: %v", err)
+	}
+	if err = u.UnmarshalBinary(bin[1:]); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("[FAIL] expected ErrInvalidID, got %v", err)
+	}
+
+	ids := []UUID{MustParseUUID("ffffffff-0000-7000-8000-000000000000"), {}, MustParseUUID("00000000-0000-7000-8000-000000000001")}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Compare(ids[j]) < 0 })
+	if !ids[0].IsZero() || ids[2][0] != 0xff || ids[1].Compare(ids[1]) != 0 {
+		t.Errorf("[FAIL] Compare sorted UUIDs wrong: %v", ids)
+	}
+}
+
+func Benchmark_UUID(b *testing.B) {
+	b.Run("v4", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			NewUUIDv4()
+		}
+	})
+	b.Run("v4/secure", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			NewSecureUUIDv4()
+		}
+	})
+	b.Run("v7", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			NewUUIDv7()
+		}
+	})
+	b.Run("String", func(b *testing.B) {
+		u := NewUUIDv4()
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			_ = u.String()
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package entropy
+
+import (
+	crip "crypto/rand"
+	"encoding/binary"
+	randv2 "math/rand/v2"
+	"sync"
+)
+
+type pooledSource struct{}
+
+func (pooledSource) Uint64() uint64 {
+	r := lolXD.Get()
+	v := r.Uint64()
+	lolXD.Put(r)
+	return v
+}
+
+// PooledSource is a math/rand/v2 Source backed by the same pooled SplitMix64 generators as the rest of the package.
+// Unlike the Sources in math/rand/v2, it is safe for concurrent use.
+var PooledSource randv2.Source = pooledSource{}
+
+// a seeded [Source] and [LockedRand] can be used with math/rand/v2 as they are.
+var (
+	_ randv2.Source = (*Source)(nil)
+	_ randv2.Source = (*LockedRand)(nil)
+)
+
+// RandV2 is a math/rand/v2 Rand drawing from [PooledSource]. It is safe for concurrent use, making it a
+// drop-in for the top-level math/rand/v2 functions that keeps the pooled fast path.
+var RandV2 = randv2.New(PooledSource)
+
+// seed32 returns 32 bytes of seed material from crypto/rand, or from the pinned seed if [PinSeed] is in effect.
+func seed32() (seed [32]byte) {
+	if src := pinned.Load(); src != nil {
+		r := src.derive()
+		for i := 0; i < len(seed); i += 8 {
+			binary.LittleEndian.PutUint64(seed[i:], r.Uint64())
+		}
+		return seed
+	}
+	_, _ = crip.Read(seed[:])
+	return seed
+}
+
+// NewChaCha8 returns a new math/rand/v2 ChaCha8 generator seeded from crypto/rand. ChaCha8 is slower than
+// SplitMix64, but its output can't be predicted from previous output. It is not safe for concurrent use.
+func NewChaCha8() *randv2.ChaCha8 {
+	return randv2.NewChaCha8(seed32())
+}
+
+// NewPCG returns a new math/rand/v2 PCG generator seeded from crypto/rand. It is not safe for concurrent use.
+func NewPCG() *randv2.PCG {
+	seed := seed32()
+	return randv2.NewPCG(binary.LittleEndian.Uint64(seed[:8]), binary.LittleEndian.Uint64(seed[8:16]))
+}
+
+type sourcePool struct {
+	sync.Pool
+	fresh func() randv2.Source
+}
+
+func newSourcePool(fresh func() randv2.Source) *sourcePool {
+	return &sourcePool{
+		Pool:  sync.Pool{New: func() interface{} { return fresh() }},
+		fresh: fresh,
+	}
+}
+
+func (p *sourcePool) Uint64() uint64 {
+	// pinned generators bypass the pool for the same reason they do in lolXD.
+	if pinned.Load() != nil {
+		return p.fresh().Uint64()
+	}
+	src := p.Pool.Get().(randv2.Source)
+	v := src.Uint64()
+	p.Pool.Put(src)
+	return v
+}
+
+var (
+	// PooledChaCha8 is a math/rand/v2 Source backed by a pool of [NewChaCha8] generators. It is safe for concurrent use.
+	PooledChaCha8 randv2.Source = newSourcePool(func() randv2.Source { return NewChaCha8() })
+	// PooledPCG is a math/rand/v2 Source backed by a pool of [NewPCG] generators. It is safe for concurrent use.
+	PooledPCG randv2.Source = newSourcePool(func() randv2.Source { return NewPCG() })
+)
+
+// intType mirrors the constraint of math/rand/v2's N, any integer type including time.Duration.
+type intType interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// N returns a uniform random value in [0, n) from the pooled generators, without modulo bias. It panics if n <= 0.
+// It is the pooled equivalent of math/rand/v2's N, and works with any integer type, e.g.
+//
+//	time.Sleep(entropy.N(500 * time.Millisecond))
+func N[T intType](n T) T {
+	if n <= 0 {
+		panic("entropy: invalid argument to N")
+	}
+	return T(Uint64n(uint64(n)))
+}
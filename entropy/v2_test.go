@@ -0,0 +1,147 @@
+package entropy
+
+import (
+	randv2 "math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_N(t *testing.T) {
+	t.Parallel()
+	counts := make([]int, 7)
+	for i := 0; i < draws; i++ {
+		counts[N(7)]++
+	}
+	checkUniform(t, "N", counts)
+
+	for i := 0; i < 1000; i++ {
+		if d := N(250 * time.Millisecond); d < 0 || d >= 250*time.Millisecond {
+			t.Fatalf("[FAIL] N(250ms) returned %s", d)
+		}
+		if v := N(uint8(200)); v >= 200 {
+			t.Fatalf("[FAIL] N(uint8(200)) returned %d", v)
+		}
+		if v := N(int64(1) << 62); v < 0 {
+			t.Fatalf("[FAIL] N(1<<62) returned %d", v)
+		}
+	}
+
+	for name, f := range map[string]func(){
+		"N(0)":             func() { N(0) },
+		"N(-1)":            func() { N(-1) },
+		"N(-time.Second)":  func() { N(-time.Second) },
+		"N(uint(0))":       func() { N(uint(0)) },
+		"N(int8(-128))":    func() { N(int8(-128)) },
+		"N(time.Duration)": func() { N(time.Duration(0)) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("[FAIL] %s should panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func Test_V2Sources(t *testing.T) {
+	t.Parallel()
+	for name, src := range map[string]randv2.Source{
+		"PooledSource":  PooledSource,
+		"PooledChaCha8": PooledChaCha8,
+		"PooledPCG":     PooledPCG,
+	} {
+		r := randv2.New(src)
+		wg := &sync.WaitGroup{}
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for n := 0; n < 1000; n++ {
+					if v := r.IntN(10); v < 0 || v >= 10 {
+						t.Errorf("[FAIL] %s: IntN(10) returned %d", name, v)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+
+		counts := make([]int, 9)
+		for i := 0; i < draws/5; i++ {
+			counts[r.IntN(9)]++
+		}
+		checkUniform(t, name, counts)
+	}
+
+	if RandV2.Uint64() == RandV2.Uint64() {
+		t.Errorf("[FAIL] RandV2 returned the same value twice!")
+	}
+	if NewChaCha8().Uint64() == NewChaCha8().Uint64() {
+		t.Errorf("[FAIL] NewChaCha8 generators returned the same first value!")
+	}
+	if NewPCG().Uint64() == NewPCG().Uint64() {
+		t.Errorf("[FAIL] NewPCG generators returned the same first value!")
+	}
+
+	one, two := randv2.New(NewSource(7)), randv2.New(NewSource(7))
+	for i := 0; i < 100; i++ {
+		if a, b := one.Int64(), two.Int64(); a != b {
+			t.Fatalf("[FAIL] seeded Sources diverged through math/rand/v2: %d != %d", a, b)
+		}
+	}
+}
+
+// Test_PinSeedV2 doesn't call t.Parallel for the same reason as Test_PinSeed.
+func Test_PinSeedV2(t *testing.T) {
+	run := func() []uint64 {
+		return []uint64{
+			NewChaCha8().Uint64(), NewPCG().Uint64(),
+			PooledChaCha8.Uint64(), PooledPCG.Uint64(), PooledSource.Uint64(),
+			uint64(N(1 << 40)),
+		}
+	}
+	restore := PinSeed(7)
+	first := run()
+	restore()
+	restore = PinSeed(7)
+	second := run()
+	restore()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("[FAIL] pinned run diverged at %d: %d != %d", i, first[i], second[i])
+		}
+	}
+}
+
+func Benchmark_V2(b *testing.B) {
+	for name, src := range map[string]randv2.Source{
+		"PooledSource":  PooledSource,
+		"PooledChaCha8": PooledChaCha8,
+		"PooledPCG":     PooledPCG,
+	} {
+		r := randv2.New(src)
+		b.Run(name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					r.IntN(1000)
+				}
+			})
+		})
+	}
+	b.Run("math/rand/v2", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				randv2.IntN(1000)
+			}
+		})
+	})
+	b.Run("N", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				N(1000)
+			}
+		})
+	})
+}
@@ -0,0 +1,226 @@
+// Package cas provides a filesystem backed content-addressable blob store built on top of [hash].
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/yunginnanet/common/hash"
+	"github.com/yunginnanet/common/xerrors"
+)
+
+var (
+	// ErrNotFound is returned when a blob does not exist in the [Store].
+	ErrNotFound = errors.New("blob not found")
+	// ErrTypeMismatch is returned when a [hash.Digest] of a different [hash.Type] than the [Store] is used.
+	ErrTypeMismatch = errors.New("digest type does not match store")
+)
+
+const tmpDir = "tmp"
+
+// Store is a content-addressable blob store rooted at a directory on disk.
+//
+// Blobs are stored at `<root>/<algo>/<aa>/<hex digest>`, where `aa` is the first byte of the digest in hex.
+// Writes are staged under `<root>/tmp` and renamed into place, so readers never observe partial blobs.
+// A Store is safe for concurrent use, including by multiple processes sharing the same root.
+type Store struct {
+	root string
+	ht   hash.Type
+}
+
+// New returns a [Store] rooted at root that addresses blobs by the given [hash.Type].
+// The directory structure is created if it does not already exist.
+func New(root string, ht hash.Type) (*Store, error) {
+	h, err := hash.NewHasher(ht)
+	if err != nil {
+		return nil, err
+	}
+	h.Release()
+	s := &Store{root: root, ht: ht}
+	for _, dir := range []string{filepath.Join(root, ht.String()), filepath.Join(root, tmpDir)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Type returns the [hash.Type] used to address blobs in this [Store].
+func (s *Store) Type() hash.Type {
+	return s.ht
+}
+
+// Path returns the on-disk path for the given digest, whether or not the blob exists.
+func (s *Store) Path(d hash.Digest) (string, error) {
+	if d.Type != s.ht {
+		return "", fmt.Errorf("%w: %s != %s", ErrTypeMismatch, d.Type.String(), s.ht.String())
+	}
+	if len(d.Sum) == 0 {
+		return "", fmt.Errorf("%w: empty digest", hash.ErrMalformedDigest)
+	}
+	h := d.Hex()
+	return filepath.Join(s.root, s.ht.String(), h[:2], h), nil
+}
+
+// Put streams r into the store, returning the [hash.Digest] of its contents.
+// If the blob already exists, the new copy is discarded.
+func (s *Store) Put(r io.Reader) (d hash.Digest, err error) {
+	h, err := hash.NewHasher(s.ht)
+	if err != nil {
+		return d, err
+	}
+	defer h.Release()
+
+	f, err := os.CreateTemp(filepath.Join(s.root, tmpDir), "put-*")
+	if err != nil {
+		return d, err
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	if _, err = f.ReadFrom(h.TeeReader(r)); err != nil {
+		return d, err
+	}
+	if err = f.Sync(); err != nil {
+		return d, err
+	}
+	if err = f.Close(); err != nil {
+		return d, err
+	}
+
+	d = hash.Digest{Type: s.ht, Sum: h.Digest()}
+	final, err := s.Path(d)
+	if err != nil {
+		return d, err
+	}
+	if _, statErr := os.Stat(final); statErr == nil {
+		return d, os.Remove(tmp)
+	}
+	if err = os.MkdirAll(filepath.Dir(final), 0o755); err != nil {
+		return d, err
+	}
+	return d, os.Rename(tmp, final)
+}
+
+// PutBytes is [Store.Put] for an in-memory slice.
+func (s *Store) PutBytes(b []byte) (hash.Digest, error) {
+	return s.Put(bytes.NewReader(b))
+}
+
+// Get opens the blob for the given digest. The caller must close the returned reader.
+func (s *Store) Get(d hash.Digest) (io.ReadCloser, error) {
+	p, err := s.Path(d)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, d.String())
+	}
+	return f, err
+}
+
+// Has reports whether the blob for the given digest exists.
+func (s *Store) Has(d hash.Digest) bool {
+	p, err := s.Path(d)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(p)
+	return err == nil
+}
+
+// Delete removes the blob for the given digest. It returns [ErrNotFound] if the blob does not exist.
+func (s *Store) Delete(d hash.Digest) error {
+	p, err := s.Path(d)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrNotFound, d.String())
+	}
+	return err
+}
+
+// Walk calls fn for the digest of every blob in the store. Returning an error from fn stops the walk.
+func (s *Store) Walk(fn func(hash.Digest) error) error {
+	base := filepath.Join(s.root, s.ht.String())
+	return filepath.WalkDir(base, func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if de.IsDir() {
+			return nil
+		}
+		d, parseErr := hash.ParseDigest(s.ht.String() + ":" + de.Name())
+		if parseErr != nil {
+			// not one of ours, leave it be.
+			return nil //nolint:nilerr
+		}
+		return fn(d)
+	})
+}
+
+// GC removes every blob for which keep returns false, along with any leftover staging files from
+// interrupted writes and empty fan-out directories. It returns the number of blobs removed.
+//
+// GC must not run concurrently with [Store.Put] on the same root, as in-flight staging files would be removed.
+// Errors are collected in an [xerrors.Errors] stack and do not stop the collection.
+func (s *Store) GC(keep func(hash.Digest) bool) (int, error) {
+	errs := xerrors.NewErrors()
+	removed := 0
+
+	walkErr := s.Walk(func(d hash.Digest) error {
+		if keep(d) {
+			return nil
+		}
+		if err := s.Delete(d); err != nil {
+			errs.Push(err)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	if walkErr != nil {
+		errs.Push(walkErr)
+	}
+
+	tmps, err := os.ReadDir(filepath.Join(s.root, tmpDir))
+	if err != nil {
+		errs.Push(err)
+	}
+	for _, t := range tmps {
+		errs.Push(os.Remove(filepath.Join(s.root, tmpDir, t.Name())))
+	}
+
+	base := filepath.Join(s.root, s.ht.String())
+	fanouts, err := os.ReadDir(base)
+	if err != nil {
+		errs.Push(err)
+	}
+	for _, fo := range fanouts {
+		if !fo.IsDir() {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(base, fo.Name()))
+		if err == nil && len(entries) == 0 {
+			errs.Push(os.Remove(filepath.Join(base, fo.Name())))
+		}
+	}
+
+	if errs.Len() > 0 {
+		return removed, errs
+	}
+	return removed, nil
+}
@@ -0,0 +1,150 @@
+package cas
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yunginnanet/common/entropy"
+	"github.com/yunginnanet/common/hash"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir(), hash.TypeSHA256)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if s.Type() != hash.TypeSHA256 {
+		t.Errorf("[FAIL] wanted sha256, got %s", s.Type().String())
+	}
+
+	dat := []byte("kayos\n")
+	d, err := s.PutBytes(dat)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if d.String() != "sha256:05a818d139a8191dceeedf340469b82ba5073e5a8483a1c98abc109a1acf2b85" {
+		t.Errorf("[FAIL] unexpected digest %s", d.String())
+	}
+	p, _ := s.Path(d)
+	if want := filepath.Join(s.root, "sha256", "05", d.Hex()); p != want {
+		t.Errorf("[FAIL] wanted path %s, got %s", want, p)
+	}
+	if !s.Has(d) {
+		t.Fatal("[FAIL] Has returned false after Put")
+	}
+
+	// duplicate puts collapse into the same blob
+	d2, err := s.Put(bytes.NewReader(dat))
+	if err != nil || !d2.Equal(d) {
+		t.Fatalf("[FAIL] duplicate Put: %v (%v)", d2, err)
+	}
+
+	rc, err := s.Get(d)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	got, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(got, dat) {
+		t.Errorf("[FAIL] Get: wanted %q, got %q", dat, got)
+	}
+
+	tmps, _ := os.ReadDir(filepath.Join(s.root, tmpDir))
+	if len(tmps) != 0 {
+		t.Errorf("[FAIL] staging files left behind: %v", tmps)
+	}
+
+	if err = s.Delete(d); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if s.Has(d) {
+		t.Error("[FAIL] Has returned true after Delete")
+	}
+	if _, err = s.Get(d); !errors.Is(err, ErrNotFound) {
+		t.Errorf("[FAIL] wanted ErrNotFound, got %v", err)
+	}
+	if err = s.Delete(d); !errors.Is(err, ErrNotFound) {
+		t.Errorf("[FAIL] wanted ErrNotFound, got %v", err)
+	}
+
+	wrong := hash.SumDigest(hash.TypeMD5, dat)
+	if _, err = s.Get(wrong); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("[FAIL] wanted ErrTypeMismatch, got %v", err)
+	}
+	if s.Has(wrong) {
+		t.Error("[FAIL] Has returned true for a mismatched type")
+	}
+	if err = s.Delete(wrong); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("[FAIL] wanted ErrTypeMismatch, got %v", err)
+	}
+	if _, err = s.Path(hash.Digest{Type: hash.TypeSHA256}); !errors.Is(err, hash.ErrMalformedDigest) {
+		t.Errorf("[FAIL] wanted ErrMalformedDigest, got %v", err)
+	}
+}
+
+func TestStoreGC(t *testing.T) {
+	t.Parallel()
+	s, err := New(t.TempDir(), hash.TypeBlake2b)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	keep := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		d, err := s.Put(strings.NewReader(entropy.RandStrWithUpper(100)))
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		keep[d.String()] = i%2 == 0
+	}
+	if err = os.WriteFile(filepath.Join(s.root, tmpDir, "put-stale"), []byte("yeet"), 0o600); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+
+	removed, err := s.GC(func(d hash.Digest) bool { return keep[d.String()] })
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if removed != 25 {
+		t.Errorf("[FAIL] wanted 25 removed, got %d", removed)
+	}
+
+	seen := 0
+	if err = s.Walk(func(d hash.Digest) error {
+		if !keep[d.String()] {
+			t.Errorf("[FAIL] %s should have been collected", d.String())
+		}
+		seen++
+		return nil
+	}); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if seen != 25 {
+		t.Errorf("[FAIL] wanted 25 remaining, got %d", seen)
+	}
+	if tmps, _ := os.ReadDir(filepath.Join(s.root, tmpDir)); len(tmps) != 0 {
+		t.Errorf("[FAIL] stale staging files were not collected: %v", tmps)
+	}
+
+	if removed, err = s.GC(func(hash.Digest) bool { return false }); err != nil || removed != 25 {
+		t.Errorf("[FAIL] wanted 25 removed, got %d (%v)", removed, err)
+	}
+	if fanouts, _ := os.ReadDir(filepath.Join(s.root, "blake2b")); len(fanouts) != 0 {
+		t.Errorf("[FAIL] empty fan-out directories were not collected: %d left", len(fanouts))
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	if _, err := New(t.TempDir(), hash.TypeNull); !errors.Is(err, hash.ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+	if _, err := New(t.TempDir(), hash.TypeHMACSHA256); !errors.Is(err, hash.ErrKeyRequired) {
+		t.Errorf("[FAIL] wanted ErrKeyRequired, got %v", err)
+	}
+}
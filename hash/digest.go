@@ -0,0 +1,133 @@
+package hash
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMalformedDigest is returned when a digest can not be parsed.
+var ErrMalformedDigest = errors.New("malformed digest")
+
+// Digest is a self-describing checksum: the [Type] that produced it alongside the raw sum.
+// Its text form is `<type>:<hex>`, e.g. `sha256:05a818d1...`.
+type Digest struct {
+	Type Type
+	Sum  []byte
+}
+
+// typeToMultihash holds the multicodec codes for the types that have one.
+// See: https://github.com/multiformats/multicodec/blob/master/table.csv
+var typeToMultihash = map[Type]uint64{
+	TypeSHA1:      0x11,
+	TypeSHA256:    0x12,
+	TypeSHA512:    0x13,
+	TypeMD5:       0xd5,
+	TypeCRC32:     0x0132,
+	TypeCRC64ECMA: 0x0164,
+	TypeBlake2b:   0xb240,
+	TypeXXH64:     0xb3e2,
+	TypeXXH3:      0xb3e3,
+}
+
+var multihashToType = func() map[uint64]Type {
+	m := make(map[uint64]Type, len(typeToMultihash))
+	for t, c := range typeToMultihash {
+		m[c] = t
+	}
+	return m
+}()
+
+// SumDigest is [Sum], but returns a [Digest].
+func SumDigest(ht Type, b []byte) Digest {
+	return Digest{Type: ht, Sum: Sum(ht, b)}
+}
+
+// Hex returns the sum as lowercase hex.
+func (d Digest) Hex() string {
+	return hex.EncodeToString(d.Sum)
+}
+
+// Base64 returns the sum using standard padded base64.
+func (d Digest) Base64() string {
+	return base64.StdEncoding.EncodeToString(d.Sum)
+}
+
+// String returns the digest in its `<type>:<hex>` text form.
+func (d Digest) String() string {
+	return d.Type.String() + ":" + d.Hex()
+}
+
+// Equal reports whether both digests are of the same type and sum. The sums are compared in constant time.
+func (d Digest) Equal(other Digest) bool {
+	return d.Type == other.Type && Verify(d.Sum, other.Sum)
+}
+
+// Multihash returns the digest encoded as a multihash: varint(code) || varint(length) || sum.
+// It returns [ErrUnknownType] if the digest's type has no assigned multicodec.
+func (d Digest) Multihash() ([]byte, error) {
+	code, ok := typeToMultihash[d.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: no multihash code for %s", ErrUnknownType, d.Type.String())
+	}
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(d.Sum))
+	buf = binary.AppendUvarint(buf, code)
+	buf = binary.AppendUvarint(buf, uint64(len(d.Sum)))
+	return append(buf, d.Sum...), nil
+}
+
+// ParseMultihash decodes a multihash produced by [Digest.Multihash].
+func ParseMultihash(mh []byte) (Digest, error) {
+	code, n := binary.Uvarint(mh)
+	if n <= 0 {
+		return Digest{}, fmt.Errorf("%w: bad multihash code", ErrMalformedDigest)
+	}
+	mh = mh[n:]
+	length, n := binary.Uvarint(mh)
+	if n <= 0 || uint64(len(mh)-n) != length {
+		return Digest{}, fmt.Errorf("%w: bad multihash length", ErrMalformedDigest)
+	}
+	ht, ok := multihashToType[code]
+	if !ok {
+		return Digest{}, fmt.Errorf("%w: multihash code 0x%x", ErrUnknownType, code)
+	}
+	return Digest{Type: ht, Sum: append([]byte(nil), mh[n:]...)}, nil
+}
+
+// ParseDigest parses a digest in its `<type>:<hex>` text form.
+func ParseDigest(s string) (Digest, error) {
+	name, sum, ok := strings.Cut(s, ":")
+	if !ok {
+		return Digest{}, fmt.Errorf("%w: missing type prefix in %q", ErrMalformedDigest, s)
+	}
+	ht, err := parseType(name)
+	if err != nil {
+		return Digest{}, err
+	}
+	raw, err := hex.DecodeString(sum)
+	if err != nil {
+		return Digest{}, fmt.Errorf("%w: %w", ErrMalformedDigest, err)
+	}
+	return Digest{Type: ht, Sum: raw}, nil
+}
+
+// MarshalText implements [encoding.TextMarshaler] using the `<type>:<hex>` form.
+func (d Digest) MarshalText() ([]byte, error) {
+	if _, ok := typeToString[d.Type]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownType, d.Type)
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], see [ParseDigest].
+func (d *Digest) UnmarshalText(text []byte) error {
+	parsed, err := ParseDigest(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
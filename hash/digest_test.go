@@ -0,0 +1,140 @@
+package hash
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"testing"
+)
+
+func TestTypeMarshaling(t *testing.T) {
+	t.Parallel()
+	for ht := range typeToString {
+		text, err := ht.MarshalText()
+		if err != nil {
+			t.Fatalf("[FAIL] %s: %s", ht.String(), err.Error())
+		}
+		var back Type
+		if err = back.UnmarshalText(text); err != nil || back != ht {
+			t.Errorf("[FAIL] text round trip: wanted %s, got %s (%v)", ht.String(), back.String(), err)
+		}
+
+		j, err := json.Marshal(struct{ Algo Type }{ht})
+		if err != nil {
+			t.Fatalf("[FAIL] %s: %s", ht.String(), err.Error())
+		}
+		var jback struct{ Algo Type }
+		if err = json.Unmarshal(j, &jback); err != nil || jback.Algo != ht {
+			t.Errorf("[FAIL] json round trip: wanted %s, got %s (%v) from %s", ht.String(), jback.Algo.String(), err, j)
+		}
+
+		bin, err := ht.MarshalBinary()
+		if err != nil {
+			t.Fatalf("[FAIL] %s: %s", ht.String(), err.Error())
+		}
+		var bback Type
+		if err = bback.UnmarshalBinary(bin); err != nil || bback != ht {
+			t.Errorf("[FAIL] binary round trip: wanted %s, got %s (%v)", ht.String(), bback.String(), err)
+		}
+	}
+
+	var ht Type
+	if err := ht.UnmarshalText([]byte("SHA256")); err != nil || ht != TypeSHA256 {
+		t.Errorf("[FAIL] case-insensitive parse failed: %s (%v)", ht.String(), err)
+	}
+	if err := ht.UnmarshalText([]byte("yeet")); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`"yeet"`), &ht); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(`5`), &ht); err == nil {
+		t.Error("[FAIL] json number should not unmarshal into a Type")
+	}
+	if _, err := Type(94).MarshalText(); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+	if _, err := Type(94).MarshalBinary(); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+	if err := ht.UnmarshalBinary([]byte{94}); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+	if err := ht.UnmarshalBinary(nil); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	algo := TypeSHA1
+	fs.Var(&algo, "algo", "hash algorithm")
+	if err := fs.Parse([]string{"-algo", "blake2b"}); err != nil || algo != TypeBlake2b {
+		t.Errorf("[FAIL] flag parse: wanted blake2b, got %s (%v)", algo.String(), err)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	t.Parallel()
+	for ht, sum := range valids {
+		d := SumDigest(ht, kayosByteSlice)
+		if !d.Equal(Digest{Type: ht, Sum: sum}) {
+			t.Errorf("[FAIL] %s: SumDigest mismatch", ht.String())
+		}
+		parsed, err := ParseDigest(d.String())
+		if err != nil || !parsed.Equal(d) {
+			t.Errorf("[FAIL] %s: ParseDigest(%q) round trip failed (%v)", ht.String(), d.String(), err)
+		}
+
+		j, err := json.Marshal(d)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		var jback Digest
+		if err = json.Unmarshal(j, &jback); err != nil || !jback.Equal(d) {
+			t.Errorf("[FAIL] %s: json round trip failed (%v) from %s", ht.String(), err, j)
+		}
+
+		mh, err := d.Multihash()
+		if _, ok := typeToMultihash[ht]; !ok {
+			if !errors.Is(err, ErrUnknownType) {
+				t.Errorf("[FAIL] %s: wanted ErrUnknownType, got %v", ht.String(), err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("[FAIL] %s: %s", ht.String(), err.Error())
+		}
+		mback, err := ParseMultihash(mh)
+		if err != nil || !mback.Equal(d) {
+			t.Errorf("[FAIL] %s: multihash round trip failed (%v)", ht.String(), err)
+		}
+	}
+
+	d := SumDigest(TypeSHA256, kayosByteSlice)
+	if d.Base64() != kayosSHA256 {
+		t.Errorf("[FAIL] Base64: wanted %s, got %s", kayosSHA256, d.Base64())
+	}
+	if d.Hex() != "05a818d139a8191dceeedf340469b82ba5073e5a8483a1c98abc109a1acf2b85" {
+		t.Errorf("[FAIL] Hex: got %s", d.Hex())
+	}
+	mh, _ := d.Multihash()
+	if mh[0] != 0x12 || mh[1] != 32 {
+		t.Errorf("[FAIL] sha256 multihash prefix: wanted 12 20, got %x", mh[:2])
+	}
+	if d.Equal(SumDigest(TypeSHA512, kayosByteSlice)) {
+		t.Error("[FAIL] digests of different types should not be equal")
+	}
+
+	for _, bad := range []string{"", "sha256", "sha256:zz", ":abcd"} {
+		if _, err := ParseDigest(bad); err == nil {
+			t.Errorf("[FAIL] ParseDigest(%q) should have failed", bad)
+		}
+	}
+	for _, bad := range [][]byte{nil, {0x12}, {0x12, 5, 1}, {0x7f, 1, 1}} {
+		if _, err := ParseMultihash(bad); err == nil {
+			t.Errorf("[FAIL] ParseMultihash(%x) should have failed", bad)
+		}
+	}
+	if _, err := (Digest{Type: Type(94)}).MarshalText(); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+}
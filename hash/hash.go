@@ -5,13 +5,15 @@ import (
 	"crypto/sha1" //nolint:gosec
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"hash"
 	"hash/crc32"
 	"hash/crc64"
-	"io"
+	"hash/fnv"
 	"os"
+	"strings"
 	"sync"
 
 	"golang.org/x/crypto/blake2b"
@@ -29,6 +31,13 @@ const (
 	TypeCRC32
 	TypeCRC64ISO
 	TypeCRC64ECMA
+	TypeBlake2bKeyed
+	TypeHMACSHA256
+	TypeHMACSHA512
+	TypeFNV1a64
+	TypeFNV1a128
+	TypeXXH64
+	TypeXXH3
 )
 
 var typeToString = map[Type]string{
@@ -36,6 +45,9 @@ var typeToString = map[Type]string{
 	TypeSHA256: "sha256", TypeSHA512: "sha512",
 	TypeMD5: "md5", TypeCRC32: "crc32",
 	TypeCRC64ISO: "crc64-iso", TypeCRC64ECMA: "crc64-ecma",
+	TypeBlake2bKeyed: "blake2b-keyed", TypeHMACSHA256: "hmac-sha256",
+	TypeHMACSHA512: "hmac-sha512", TypeFNV1a64: "fnv1a-64",
+	TypeFNV1a128: "fnv1a-128", TypeXXH64: "xxh64", TypeXXH3: "xxh3",
 }
 
 var stringToType = map[string]Type{
@@ -43,6 +55,9 @@ var stringToType = map[string]Type{
 	"sha256": TypeSHA256, "sha512": TypeSHA512,
 	"md5": TypeMD5, "crc32": TypeCRC32,
 	"crc64-iso": TypeCRC64ISO, "crc64-ecma": TypeCRC64ECMA,
+	"blake2b-keyed": TypeBlake2bKeyed, "hmac-sha256": TypeHMACSHA256,
+	"hmac-sha512": TypeHMACSHA512, "fnv1a-64": TypeFNV1a64,
+	"fnv1a-128": TypeFNV1a128, "xxh64": TypeXXH64, "xxh3": TypeXXH3,
 }
 
 func StringToType(s string) Type {
@@ -61,6 +76,77 @@ func (t Type) String() string {
 	return s
 }
 
+func parseType(s string) (Type, error) {
+	t, ok := stringToType[strings.ToLower(strings.TrimSpace(s))]
+	if !ok {
+		return TypeNull, fmt.Errorf("%w: %q", ErrUnknownType, s)
+	}
+	return t, nil
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown types fail to marshal.
+func (t Type) MarshalText() ([]byte, error) {
+	s, ok := typeToString[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownType, t)
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. Names are matched case-insensitively.
+// Unlike [StringToType], unknown names return [ErrUnknownType] instead of silently becoming [TypeNull].
+func (t *Type) UnmarshalText(text []byte) error {
+	parsed, err := parseType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON implements [json.Marshaler], encoding the type as its name.
+func (t Type) MarshalJSON() ([]byte, error) {
+	text, err := t.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler] as a single byte.
+func (t Type) MarshalBinary() ([]byte, error) {
+	if _, ok := typeToString[t]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownType, t)
+	}
+	return []byte{byte(t)}, nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (t *Type) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return fmt.Errorf("%w: bad length %d", ErrUnknownType, len(data))
+	}
+	if _, ok := typeToString[Type(data[0])]; !ok {
+		return fmt.Errorf("%w: %d", ErrUnknownType, data[0])
+	}
+	*t = Type(data[0])
+	return nil
+}
+
+// Set implements [flag.Value], so a *Type may be passed to [flag.Var].
+func (t *Type) Set(s string) error {
+	return t.UnmarshalText([]byte(s))
+}
+
 var (
 	sha1Pool = &sync.Pool{
 		New: func() interface{} {
@@ -106,46 +192,77 @@ var (
 			return h
 		},
 	}
+	fnv1a64Pool = &sync.Pool{
+		New: func() interface{} {
+			return fnv.New64a()
+		},
+	}
+	fnv1a128Pool = &sync.Pool{
+		New: func() interface{} {
+			return fnv.New128a()
+		},
+	}
+	xxh64Pool = &sync.Pool{
+		New: func() interface{} {
+			return newXXH64()
+		},
+	}
+	xxh3Pool = &sync.Pool{
+		New: func() interface{} {
+			return newXXH3()
+		},
+	}
 )
 
-func Sum(ht Type, b []byte) []byte {
-	var h hash.Hash
+// ErrUnknownType is returned when a [Type] has no backing hash implementation.
+var ErrUnknownType = errors.New("unknown hash type")
+
+func typeToPool(ht Type) *sync.Pool {
 	switch ht {
 	case TypeBlake2b:
-		h = blake2bPool.Get().(hash.Hash)
-		defer blake2bPool.Put(h)
+		return blake2bPool
 	case TypeSHA1:
-		h = sha1Pool.Get().(hash.Hash)
-		defer sha1Pool.Put(h)
+		return sha1Pool
 	case TypeSHA256:
-		h = sha256Pool.Get().(hash.Hash)
-		defer sha256Pool.Put(h)
+		return sha256Pool
 	case TypeSHA512:
-		h = sha512Pool.Get().(hash.Hash)
-		defer sha512Pool.Put(h)
+		return sha512Pool
 	case TypeMD5:
-		h = md5Pool.Get().(hash.Hash)
-		defer md5Pool.Put(h)
+		return md5Pool
 	case TypeCRC32:
-		h = crc32Pool.Get().(hash.Hash)
-		defer crc32Pool.Put(h)
+		return crc32Pool
 	case TypeCRC64ISO:
-		h = crc64ISOPool.Get().(hash.Hash)
-		defer crc64ISOPool.Put(h)
+		return crc64ISOPool
 	case TypeCRC64ECMA:
-		h = crc64ECMAPool.Get().(hash.Hash)
-		defer crc64ECMAPool.Put(h)
-
+		return crc64ECMAPool
+	case TypeFNV1a64:
+		return fnv1a64Pool
+	case TypeFNV1a128:
+		return fnv1a128Pool
+	case TypeXXH64:
+		return xxh64Pool
+	case TypeXXH3:
+		return xxh3Pool
 	default:
 		return nil
 	}
+}
+
+func Sum(ht Type, b []byte) []byte {
+	p := typeToPool(ht)
+	if p == nil {
+		return nil
+	}
+	h := p.Get().(hash.Hash)
+	defer p.Put(h)
 	h.Write(b)
 	sum := h.Sum(nil)
 	h.Reset()
 	return sum
 }
 
-// SumFile will attempt to calculate a blake2b checksum of the given file path's contents.
+// SumFile will attempt to calculate a checksum of the given file path's contents using the given [Type].
+// The file is streamed through [SumReader], so memory use does not grow with the size of the file.
 func SumFile(ht Type, path string) (buf []byte, err error) {
 	var f *os.File
 	f, err = os.Open(path)
@@ -154,15 +271,36 @@ func SumFile(ht Type, path string) (buf []byte, err error) {
 	}
 
 	defer func() {
-		if closeErr := f.Close(); err != nil {
-			err = fmt.Errorf("failed to close file during BlakeFileChecksum: %w", closeErr)
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file during SumFile: %w", closeErr)
 		}
 	}()
 
-	buf, _ = io.ReadAll(f)
-	if len(buf) == 0 {
+	var n int64
+	if buf, n, err = sumReader(ht, f); err != nil {
+		return nil, err
+	}
+	if n == 0 {
 		return nil, errors.New("file is empty")
 	}
 
-	return Sum(ht, buf), nil
+	return buf, nil
+}
+
+// MultiSumFile is the file path variant of [MultiSum]. The file is read exactly once regardless of
+// how many types are requested.
+func MultiSumFile(types []Type, path string) (sums map[Type][]byte, err error) {
+	var f *os.File
+	f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close file during MultiSumFile: %w", closeErr)
+		}
+	}()
+
+	return MultiSum(types, f)
 }
@@ -21,6 +21,10 @@ const (
 	kayosCRC32     = "xtig5w=="
 	kayosCRC64ISO  = "YVx8IpQawAA="
 	kayosCRC64ECMA = "Nn5+vneo4j4="
+	kayosFNV1a64   = "E/U/5BbUuAg="
+	kayosFNV1a128  = "nmubntI8ZL9u/JoVmaA2gA=="
+	kayosXXH64     = "V+ZeMRkBqZM="
+	kayosXXH3      = "BV7kMTfeQTI="
 )
 
 var kayosByteSlice = []byte{107, 97, 121, 111, 115, 10}
@@ -34,6 +38,10 @@ var (
 	ogCRC32, _     = base64.StdEncoding.DecodeString(kayosCRC32)
 	ogCRC64ISO, _  = base64.StdEncoding.DecodeString(kayosCRC64ISO)
 	ogCRC64ECMA, _ = base64.StdEncoding.DecodeString(kayosCRC64ECMA)
+	ogFNV1a64, _   = base64.StdEncoding.DecodeString(kayosFNV1a64)
+	ogFNV1a128, _  = base64.StdEncoding.DecodeString(kayosFNV1a128)
+	ogXXH64, _     = base64.StdEncoding.DecodeString(kayosXXH64)
+	ogXXH3, _      = base64.StdEncoding.DecodeString(kayosXXH3)
 	valids         = map[Type][]byte{
 		TypeSHA1:      ogsha1,
 		TypeSHA256:    ogsha256,
@@ -43,6 +51,10 @@ var (
 		TypeCRC64ISO:  ogCRC64ISO,
 		TypeCRC64ECMA: ogCRC64ECMA,
 		TypeBlake2b:   ogBlake2b,
+		TypeFNV1a64:   ogFNV1a64,
+		TypeFNV1a128:  ogFNV1a128,
+		TypeXXH64:     ogXXH64,
+		TypeXXH3:      ogXXH3,
 	}
 )
 
@@ -0,0 +1,140 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	// ErrKeyRequired is returned when a keyed [Type] is used without a key.
+	ErrKeyRequired = errors.New("hash type requires a key")
+	// ErrNotKeyed is returned when a key is given for a [Type] that does not take one.
+	ErrNotKeyed = errors.New("hash type is not keyed")
+)
+
+// Keyed reports whether the [Type] requires a key, see [NewKeyedPool] and [SumKeyed].
+func (t Type) Keyed() bool {
+	switch t {
+	case TypeBlake2bKeyed, TypeHMACSHA256, TypeHMACSHA512:
+		return true
+	default:
+		return false
+	}
+}
+
+func newKeyedFunc(ht Type, key []byte) (func() hash.Hash, error) {
+	if ht.Keyed() && len(key) == 0 {
+		return nil, ErrKeyRequired
+	}
+	key = append([]byte(nil), key...)
+	switch ht {
+	case TypeBlake2bKeyed:
+		// validate the key once up front so the pool's New func can't fail
+		if _, err := blake2b.New(blake2b.Size, key); err != nil {
+			return nil, err
+		}
+		return func() hash.Hash {
+			h, _ := blake2b.New(blake2b.Size, key)
+			return h
+		}, nil
+	case TypeHMACSHA256:
+		return func() hash.Hash { return hmac.New(sha256.New, key) }, nil
+	case TypeHMACSHA512:
+		return func() hash.Hash { return hmac.New(sha512.New, key) }, nil
+	default:
+		if typeToPool(ht) != nil {
+			return nil, ErrNotKeyed
+		}
+		return nil, ErrUnknownType
+	}
+}
+
+// KeyedPool pools instances of a keyed [Type] for a single key, so that repeated use of the same key
+// (e.g. a webhook secret) does not re-derive the keyed state. The key is only held by the pool itself,
+// so it is released along with the pool once the caller drops it.
+type KeyedPool struct {
+	t Type
+	p *sync.Pool
+}
+
+// NewKeyedPool returns a [KeyedPool] for the keyed [Type] using the given key.
+// It returns [ErrKeyRequired] if key is empty, and [ErrNotKeyed] if the type does not take a key.
+func NewKeyedPool(ht Type, key []byte) (*KeyedPool, error) {
+	newFunc, err := newKeyedFunc(ht, key)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyedPool{t: ht, p: &sync.Pool{
+		New: func() interface{} {
+			return newFunc()
+		},
+	}}, nil
+}
+
+// Type returns the keyed [Type] of the pool.
+func (kp *KeyedPool) Type() Type {
+	return kp.t
+}
+
+// Hasher returns a [Hasher] from the pool. Call [Hasher.Release] to return it.
+func (kp *KeyedPool) Hasher() *Hasher {
+	h := kp.p.Get().(hash.Hash)
+	h.Reset()
+	return &Hasher{Hash: h, t: kp.t, p: kp.p}
+}
+
+// Sum returns the keyed checksum (or MAC) of b.
+func (kp *KeyedPool) Sum(b []byte) []byte {
+	h := kp.Hasher()
+	_, _ = h.Write(b)
+	sum := h.Digest()
+	h.Release()
+	return sum
+}
+
+// Verify computes the keyed checksum of b and compares it against mac in constant time.
+func (kp *KeyedPool) Verify(b, mac []byte) bool {
+	return Verify(mac, kp.Sum(b))
+}
+
+// NewKeyedHasher returns a [Hasher] for a keyed [Type] using the given key.
+// Nothing is cached between calls; use a [KeyedPool] when the same key is used repeatedly.
+func NewKeyedHasher(ht Type, key []byte) (*Hasher, error) {
+	kp, err := NewKeyedPool(ht, key)
+	if err != nil {
+		return nil, err
+	}
+	return kp.Hasher(), nil
+}
+
+// SumKeyed returns the keyed checksum (or MAC) of b using the given keyed [Type] and key.
+// Nothing is cached between calls; use a [KeyedPool] when the same key is used repeatedly.
+func SumKeyed(ht Type, key, b []byte) ([]byte, error) {
+	kp, err := NewKeyedPool(ht, key)
+	if err != nil {
+		return nil, err
+	}
+	return kp.Sum(b), nil
+}
+
+// Verify reports whether two checksums are equal using a constant-time comparison.
+// It should always be used over [bytes.Equal] when comparing MACs.
+func Verify(expected, actual []byte) bool {
+	return subtle.ConstantTimeCompare(expected, actual) == 1
+}
+
+// VerifyKeyed computes the keyed checksum of b and compares it against mac in constant time.
+func VerifyKeyed(ht Type, key, b, mac []byte) (bool, error) {
+	kp, err := NewKeyedPool(ht, key)
+	if err != nil {
+		return false, err
+	}
+	return kp.Verify(b, mac), nil
+}
@@ -0,0 +1,119 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestSumKeyed(t *testing.T) {
+	t.Parallel()
+	key := []byte("Jefe")
+	msg := []byte("what do ya want for nothing?")
+
+	// RFC 4231 test case 2
+	hmacs := map[Type]string{
+		TypeHMACSHA256: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		TypeHMACSHA512: "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea250554" +
+			"9758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737",
+	}
+	b2, _ := blake2b.New(blake2b.Size, key)
+	_, _ = b2.Write(msg)
+	hmacs[TypeBlake2bKeyed] = hex.EncodeToString(b2.Sum(nil))
+
+	for k, v := range hmacs {
+		typeToTest := k
+		want, _ := hex.DecodeString(v)
+		t.Run(typeToTest.String(), func(t *testing.T) {
+			t.Parallel()
+			if StringToType(typeToTest.String()) != typeToTest {
+				t.Errorf("[FAIL] %s did not survive StringToType", typeToTest.String())
+			}
+			if !typeToTest.Keyed() {
+				t.Errorf("[FAIL] %s should be keyed", typeToTest.String())
+			}
+			// run a few times to make sure calls don't leak state into each other
+			for i := 0; i < 5; i++ {
+				got, err := SumKeyed(typeToTest, key, msg)
+				if err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("[FAIL] %s: wanted %x, got %x", typeToTest.String(), want, got)
+				}
+			}
+			ok, err := VerifyKeyed(typeToTest, key, msg, want)
+			if err != nil || !ok {
+				t.Errorf("[FAIL] VerifyKeyed failed on a valid MAC: %v", err)
+			}
+			if ok, _ = VerifyKeyed(typeToTest, []byte("nope"), msg, want); ok {
+				t.Errorf("[FAIL] VerifyKeyed passed with the wrong key")
+			}
+			kp, err := NewKeyedPool(typeToTest, key)
+			if err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			if kp.Type() != typeToTest {
+				t.Errorf("[FAIL] KeyedPool.Type() = %s", kp.Type().String())
+			}
+			// run a few times so that pooled instances get reused
+			for i := 0; i < 5; i++ {
+				if got := kp.Sum(msg); !bytes.Equal(got, want) {
+					t.Fatalf("[FAIL] KeyedPool %s: wanted %x, got %x", typeToTest.String(), want, got)
+				}
+			}
+			if !kp.Verify(msg, want) || kp.Verify([]byte("nope"), want) {
+				t.Errorf("[FAIL] KeyedPool.Verify gave the wrong answer")
+			}
+			if Sum(typeToTest, msg) != nil {
+				t.Errorf("[FAIL] Sum should return nil for keyed types")
+			}
+			if _, err = NewHasher(typeToTest); !errors.Is(err, ErrKeyRequired) {
+				t.Errorf("[FAIL] wanted ErrKeyRequired, got %v", err)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		if _, err := SumKeyed(TypeSHA256, key, msg); !errors.Is(err, ErrNotKeyed) {
+			t.Errorf("[FAIL] wanted ErrNotKeyed, got %v", err)
+		}
+		if _, err := SumKeyed(TypeNull, key, msg); !errors.Is(err, ErrUnknownType) {
+			t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+		}
+		if _, err := SumKeyed(TypeBlake2bKeyed, nil, msg); !errors.Is(err, ErrKeyRequired) {
+			t.Errorf("[FAIL] wanted ErrKeyRequired, got %v", err)
+		}
+		for _, kt := range []Type{TypeBlake2bKeyed, TypeHMACSHA256, TypeHMACSHA512} {
+			if _, err := SumKeyed(kt, []byte{}, msg); !errors.Is(err, ErrKeyRequired) {
+				t.Errorf("[FAIL] %s: wanted ErrKeyRequired for an empty key, got %v", kt.String(), err)
+			}
+			if _, err := NewKeyedPool(kt, nil); !errors.Is(err, ErrKeyRequired) {
+				t.Errorf("[FAIL] %s: wanted ErrKeyRequired from NewKeyedPool, got %v", kt.String(), err)
+			}
+		}
+		if _, err := NewKeyedPool(TypeSHA256, key); !errors.Is(err, ErrNotKeyed) {
+			t.Errorf("[FAIL] wanted ErrNotKeyed, got %v", err)
+		}
+		if _, err := SumKeyed(TypeBlake2bKeyed, make([]byte, 65), msg); err == nil {
+			t.Errorf("[FAIL] oversized blake2b key should have failed")
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+	if !Verify(ogsha256, Sum(TypeSHA256, kayosByteSlice)) {
+		t.Error("[FAIL] Verify failed on equal input")
+	}
+	if Verify(ogsha256, ogsha1) {
+		t.Error("[FAIL] Verify passed on unequal input")
+	}
+	if Verify(ogsha256, nil) {
+		t.Error("[FAIL] Verify passed on nil input")
+	}
+}
@@ -0,0 +1,330 @@
+package hash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/yunginnanet/common/xerrors"
+)
+
+// ManifestStyle selects the line format used when writing a checksum manifest.
+type ManifestStyle int8
+
+const (
+	// StyleGNU is the untagged GNU coreutils format, e.g: `<hex>  path`.
+	StyleGNU ManifestStyle = iota
+	// StyleBSD is the tagged BSD format, e.g: `SHA256 (path) = <hex>`.
+	StyleBSD
+)
+
+var (
+	// ErrMalformedLine is returned when a manifest line can not be parsed.
+	ErrMalformedLine = errors.New("malformed manifest line")
+	// ErrChecksumMismatch is returned when a file does not match the checksum recorded in a manifest.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// ManifestEntry is a single line of a checksum manifest.
+type ManifestEntry struct {
+	Path string
+	Type Type
+	Sum  []byte
+	// Binary is set when the GNU binary mode marker ('*') was present. It has no effect on hashing.
+	Binary bool
+}
+
+// ManifestResult is the outcome of verifying a single [ManifestEntry].
+type ManifestResult struct {
+	ManifestEntry
+	Actual []byte
+	OK     bool
+	Err    error
+}
+
+// bsdTags are the tags coreutils uses for tagged output where they differ from upper-casing [Type.String].
+var bsdTags = map[Type]string{
+	TypeBlake2b: "BLAKE2b",
+}
+
+func bsdTag(ht Type) string {
+	if tag, ok := bsdTags[ht]; ok {
+		return tag
+	}
+	return strings.ToUpper(ht.String())
+}
+
+// lengthToTypes maps hex digest lengths to the types that could have produced them.
+// Lengths that collide (e.g. sha512 and blake2b) are resolved by computing every candidate.
+var lengthToTypes = map[int][]Type{
+	hex.EncodedLen(4):  {TypeCRC32},
+	hex.EncodedLen(8):  {TypeCRC64ISO, TypeCRC64ECMA, TypeFNV1a64, TypeXXH64, TypeXXH3},
+	hex.EncodedLen(16): {TypeMD5, TypeFNV1a128},
+	hex.EncodedLen(20): {TypeSHA1},
+	hex.EncodedLen(32): {TypeSHA256},
+	hex.EncodedLen(64): {TypeSHA512, TypeBlake2b},
+}
+
+func escapePath(p string) (string, bool) {
+	if !strings.ContainsAny(p, "\\\n\r") {
+		return p, false
+	}
+	r := strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r")
+	return r.Replace(p), true
+}
+
+func unescapePath(p string) string {
+	r := strings.NewReplacer("\\\\", "\\", "\\n", "\n", "\\r", "\r")
+	return r.Replace(p)
+}
+
+// Format renders the entry as a single manifest line (without a trailing newline) in the given style.
+// Paths containing backslashes or newlines are escaped the same way coreutils escapes them.
+func (e ManifestEntry) Format(style ManifestStyle) string {
+	p, escaped := escapePath(e.Path)
+	var prefix string
+	if escaped {
+		prefix = "\\"
+	}
+	if style == StyleBSD {
+		return prefix + bsdTag(e.Type) + " (" + p + ") = " + hex.EncodeToString(e.Sum)
+	}
+	mode := " "
+	if e.Binary {
+		mode = "*"
+	}
+	return prefix + hex.EncodeToString(e.Sum) + " " + mode + p
+}
+
+// ParseManifestLine parses a single GNU or BSD style manifest line.
+//
+// Untagged GNU lines do not record the algorithm used, so the returned entry's Type is [TypeNull]
+// unless the digest length only matches a single known [Type].
+func ParseManifestLine(line string) (ManifestEntry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	var escaped bool
+	if strings.HasPrefix(line, "\\") {
+		escaped = true
+		line = line[1:]
+	}
+
+	var e ManifestEntry
+	var err error
+	if i := strings.IndexByte(line, ' '); i > 0 && strings.HasPrefix(line[i:], " (") && !isHex(line[:i]) {
+		e, err = parseBSD(line, i)
+	} else {
+		e, err = parseGNU(line)
+	}
+	if err != nil {
+		return e, err
+	}
+
+	if escaped {
+		e.Path = unescapePath(e.Path)
+	}
+	return e, nil
+}
+
+func parseBSD(line string, i int) (ManifestEntry, error) {
+	var e ManifestEntry
+	j := strings.LastIndex(line, ") = ")
+	if j < i {
+		return e, fmt.Errorf("%w: %q", ErrMalformedLine, line)
+	}
+	e.Type = StringToType(strings.ToLower(line[:i]))
+	if e.Type == TypeNull {
+		return e, fmt.Errorf("%w: unknown tag %q", ErrMalformedLine, line[:i])
+	}
+	e.Path = line[i+2 : j]
+	sum, err := hex.DecodeString(line[j+4:])
+	if err != nil {
+		return e, fmt.Errorf("%w: %w", ErrMalformedLine, err)
+	}
+	e.Sum = sum
+	return e, nil
+}
+
+func parseGNU(line string) (ManifestEntry, error) {
+	var e ManifestEntry
+	i := strings.IndexByte(line, ' ')
+	if i < 1 || len(line) < i+3 {
+		return e, fmt.Errorf("%w: %q", ErrMalformedLine, line)
+	}
+	sum, err := hex.DecodeString(line[:i])
+	if err != nil {
+		return e, fmt.Errorf("%w: %w", ErrMalformedLine, err)
+	}
+	switch line[i+1] {
+	case ' ':
+	case '*':
+		e.Binary = true
+	default:
+		return e, fmt.Errorf("%w: %q", ErrMalformedLine, line)
+	}
+	e.Sum = sum
+	e.Path = line[i+2:]
+	if candidates := lengthToTypes[len(line[:i])]; len(candidates) == 1 {
+		e.Type = candidates[0]
+	}
+	return e, nil
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// WriteManifest walks dir and writes a checksum line for every regular file found to w, using the given
+// [Type] and [ManifestStyle]. Paths are written relative to dir using forward slashes, in lexical order.
+//
+// Files that fail to hash are skipped and their errors collected in an [xerrors.Errors] stack,
+// which is returned alongside the entries that were written.
+func WriteManifest(dir string, ht Type, w io.Writer, style ManifestStyle) ([]ManifestEntry, error) {
+	if typeToPool(ht) == nil {
+		return nil, ErrUnknownType
+	}
+
+	errs := xerrors.NewErrors()
+	entries := make([]ManifestEntry, 0)
+	bw := bufio.NewWriter(w)
+
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs.Push(err)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			errs.Push(err)
+			return nil
+		}
+		sums, err := MultiSumFile([]Type{ht}, path)
+		if err != nil {
+			errs.Push(fmt.Errorf("%s: %w", rel, err))
+			return nil
+		}
+		e := ManifestEntry{Path: filepath.ToSlash(rel), Type: ht, Sum: sums[ht]}
+		if _, err = bw.WriteString(e.Format(style) + "\n"); err != nil {
+			return err
+		}
+		entries = append(entries, e)
+		return nil
+	})
+
+	if walkErr != nil {
+		errs.Push(walkErr)
+	}
+	errs.Push(bw.Flush())
+
+	if errs.Len() > 0 {
+		return entries, errs
+	}
+	return entries, nil
+}
+
+// VerifyManifest reads a manifest in either GNU or BSD style from r and verifies every listed file,
+// resolving relative paths against the current working directory. See [VerifyManifestIn].
+func VerifyManifest(r io.Reader) ([]ManifestResult, error) {
+	return VerifyManifestIn(r, "", TypeNull)
+}
+
+// VerifyManifestIn reads a manifest from r and verifies every listed file, resolving relative paths against dir.
+//
+// ht is used for untagged lines; if it is [TypeNull] the algorithm is inferred from the digest length.
+// When a length is ambiguous (sha512 and blake2b both produce 64 bytes) every candidate is computed in a
+// single read and the line passes if any of them match.
+//
+// A result is returned for every non-blank line. Malformed lines, unreadable files and mismatches are
+// collected in an [xerrors.Errors] stack which is returned as the error if non-empty.
+func VerifyManifestIn(r io.Reader, dir string, ht Type) ([]ManifestResult, error) {
+	errs := xerrors.NewErrors()
+	results := make([]ManifestResult, 0)
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		e, err := ParseManifestLine(line)
+		if err != nil {
+			err = fmt.Errorf("line %d: %w", lineNo, err)
+			errs.Push(err)
+			results = append(results, ManifestResult{Err: err})
+			continue
+		}
+		res := verifyEntry(e, dir, ht)
+		if res.Err != nil {
+			errs.Push(res.Err)
+		}
+		results = append(results, res)
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs.Push(err)
+	}
+
+	if errs.Len() > 0 {
+		return results, errs
+	}
+	return results, nil
+}
+
+func verifyEntry(e ManifestEntry, dir string, ht Type) ManifestResult {
+	res := ManifestResult{ManifestEntry: e}
+
+	var candidates []Type
+	switch {
+	case e.Type != TypeNull:
+		candidates = []Type{e.Type}
+	case ht != TypeNull:
+		candidates = []Type{ht}
+	default:
+		candidates = lengthToTypes[hex.EncodedLen(len(e.Sum))]
+	}
+	if len(candidates) == 0 {
+		res.Err = fmt.Errorf("%s: %w", e.Path, ErrUnknownType)
+		return res
+	}
+
+	path := filepath.FromSlash(e.Path)
+	if dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	sums, err := MultiSumFile(candidates, path)
+	if err != nil {
+		res.Err = fmt.Errorf("%s: %w", e.Path, err)
+		return res
+	}
+
+	for _, c := range candidates {
+		if bytes.Equal(sums[c], e.Sum) {
+			res.Type = c
+			res.Actual = sums[c]
+			res.OK = true
+			return res
+		}
+	}
+
+	res.Actual = sums[candidates[0]]
+	res.Err = fmt.Errorf("%s: %w", e.Path, ErrChecksumMismatch)
+	return res
+}
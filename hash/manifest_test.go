@@ -0,0 +1,141 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeManifestFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	for _, name := range []string{"kayos", "sub/kayos (1).txt", "empty"} {
+		dat := kayosByteSlice
+		if name == "empty" {
+			dat = nil
+		}
+		if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), dat, 0o600); err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+	}
+	return dir
+}
+
+func TestParseManifestLine(t *testing.T) {
+	t.Parallel()
+	sha := hex.EncodeToString(ogsha256)
+	cases := map[string]ManifestEntry{
+		sha + "  kayos":                                      {Path: "kayos", Type: TypeSHA256, Sum: ogsha256},
+		sha + " *kayos":                                      {Path: "kayos", Type: TypeSHA256, Sum: ogsha256, Binary: true},
+		sha + "  foo (1).txt":                                {Path: "foo (1).txt", Type: TypeSHA256, Sum: ogsha256},
+		"SHA256 (kayos) = " + sha:                            {Path: "kayos", Type: TypeSHA256, Sum: ogsha256},
+		"SHA256 (a) = b) = " + sha:                           {Path: "a) = b", Type: TypeSHA256, Sum: ogsha256},
+		"\\" + sha + "  new\\nline":                          {Path: "new\nline", Type: TypeSHA256, Sum: ogsha256},
+		"BLAKE2b (kayos) = " + hex.EncodeToString(ogBlake2b): {Path: "kayos", Type: TypeBlake2b, Sum: ogBlake2b},
+		hex.EncodeToString(ogBlake2b) + "  kayos":            {Path: "kayos", Type: TypeNull, Sum: ogBlake2b},
+	}
+	for line, want := range cases {
+		got, err := ParseManifestLine(line)
+		if err != nil {
+			t.Errorf("[FAIL] %q: %s", line, err.Error())
+			continue
+		}
+		if got.Path != want.Path || got.Type != want.Type || got.Binary != want.Binary || !bytes.Equal(got.Sum, want.Sum) {
+			t.Errorf("[FAIL] %q: wanted %+v, got %+v", line, want, got)
+		}
+		style := StyleGNU
+		if strings.Contains(line, " (") && !strings.HasPrefix(line, sha) {
+			style = StyleBSD
+		}
+		if want.Type != TypeNull && got.Format(style) != line {
+			t.Errorf("[FAIL] round trip: wanted %q, got %q", line, got.Format(style))
+		}
+	}
+	for _, bad := range []string{"", "yeet", "zz  kayos", sha + "_-kayos", "NOPE (kayos) = " + sha, "SHA256 (kayos) = zz"} {
+		if _, err := ParseManifestLine(bad); !errors.Is(err, ErrMalformedLine) {
+			t.Errorf("[FAIL] %q: wanted ErrMalformedLine, got %v", bad, err)
+		}
+	}
+}
+
+func TestManifestRoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, style := range []ManifestStyle{StyleGNU, StyleBSD} {
+		for k := range valids {
+			ht := k
+			st := style
+			t.Run(ht.String(), func(t *testing.T) {
+				t.Parallel()
+				dir := writeManifestFixture(t)
+				buf := new(bytes.Buffer)
+				entries, err := WriteManifest(dir, ht, buf, st)
+				if err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+				if len(entries) != 3 {
+					t.Fatalf("[FAIL] wanted 3 entries, got %d", len(entries))
+				}
+				results, err := VerifyManifestIn(bytes.NewReader(buf.Bytes()), dir, TypeNull)
+				if err != nil {
+					t.Fatalf("[FAIL] %s\n%s", err.Error(), buf.String())
+				}
+				for _, res := range results {
+					if !res.OK {
+						t.Errorf("[FAIL] %s did not verify", res.Path)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyManifestFailures(t *testing.T) {
+	t.Parallel()
+	dir := writeManifestFixture(t)
+	buf := new(bytes.Buffer)
+	if _, err := WriteManifest(dir, TypeSHA256, buf, StyleGNU); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	buf.WriteString("garbage line\n")
+	buf.WriteString(hex.EncodeToString(ogsha256) + "  missing\n")
+	if err := os.WriteFile(filepath.Join(dir, "kayos"), []byte("tampered"), 0o600); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+
+	results, err := VerifyManifestIn(buf, dir, TypeNull)
+	if err == nil {
+		t.Fatal("[FAIL] VerifyManifestIn should have failed")
+	}
+	if len(results) != 5 {
+		t.Fatalf("[FAIL] wanted 5 results, got %d", len(results))
+	}
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("[FAIL] wanted ErrChecksumMismatch in %v", err)
+	}
+	if !errors.Is(err, ErrMalformedLine) {
+		t.Errorf("[FAIL] wanted ErrMalformedLine in %v", err)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("[FAIL] wanted os.ErrNotExist in %v", err)
+	}
+	var ok int
+	for _, res := range results {
+		if res.OK {
+			ok++
+		}
+	}
+	if ok != 2 {
+		t.Errorf("[FAIL] wanted 2 passing results, got %d", ok)
+	}
+
+	if _, err = WriteManifest(dir, TypeNull, buf, StyleGNU); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package hash
+
+import (
+	"hash"
+	"io"
+	"sync"
+)
+
+const copyBufSize = 32 * 1024
+
+var copyBufPool = &sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, copyBufSize)
+		return &b
+	},
+}
+
+// Hasher is a pooled [hash.Hash] for a given [Type]. It implements [io.Writer], so it may be used
+// as the destination of [io.Copy] or combined with [io.MultiWriter] to digest data as it streams by.
+//
+// When finished, call [Hasher.Release] to return the underlying hash to its pool.
+// A Hasher must not be used after it has been released.
+type Hasher struct {
+	hash.Hash
+	t Type
+	p *sync.Pool
+}
+
+// NewHasher returns a [Hasher] backed by the pool for the given [Type].
+// It returns [ErrKeyRequired] for keyed types (see [NewKeyedHasher]),
+// and [ErrUnknownType] if the type has no backing implementation.
+func NewHasher(ht Type) (*Hasher, error) {
+	p := typeToPool(ht)
+	if p == nil {
+		if ht.Keyed() {
+			return nil, ErrKeyRequired
+		}
+		return nil, ErrUnknownType
+	}
+	h := p.Get().(hash.Hash)
+	h.Reset()
+	return &Hasher{Hash: h, t: ht, p: p}, nil
+}
+
+// Type returns the [Type] of the hash backing this [Hasher].
+func (h *Hasher) Type() Type {
+	return h.t
+}
+
+// Digest returns the checksum of everything written so far. It does not change the underlying hash state.
+func (h *Hasher) Digest() []byte {
+	return h.Hash.Sum(nil)
+}
+
+// ReadFrom implements [io.ReaderFrom] using a pooled copy buffer.
+func (h *Hasher) ReadFrom(r io.Reader) (int64, error) {
+	bp := copyBufPool.Get().(*[]byte)
+	n, err := io.CopyBuffer(struct{ io.Writer }{h.Hash}, r, *bp)
+	copyBufPool.Put(bp)
+	return n, err
+}
+
+// TeeReader returns an [io.Reader] that writes everything read from r into the [Hasher].
+func (h *Hasher) TeeReader(r io.Reader) io.Reader {
+	return io.TeeReader(r, h.Hash)
+}
+
+// Release resets the underlying hash and returns it to its pool.
+// It is safe to call Release more than once.
+func (h *Hasher) Release() {
+	if h.Hash == nil {
+		return
+	}
+	h.Hash.Reset()
+	h.p.Put(h.Hash)
+	h.Hash = nil
+}
+
+func sumReader(ht Type, r io.Reader) ([]byte, int64, error) {
+	h, err := NewHasher(ht)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer h.Release()
+	n, err := h.ReadFrom(r)
+	if err != nil {
+		return nil, n, err
+	}
+	return h.Digest(), n, nil
+}
+
+// SumReader streams r through a pooled hash of the given [Type] and returns the resulting checksum.
+// Memory use is constant regardless of how much data r yields.
+func SumReader(ht Type, r io.Reader) ([]byte, error) {
+	sum, _, err := sumReader(ht, r)
+	return sum, err
+}
+
+// MultiSum reads r once, fanning the data out to a pooled hash for each of the given types.
+// Duplicate types are only hashed once. It returns [ErrUnknownType] if any of the types are unknown.
+func MultiSum(types []Type, r io.Reader) (map[Type][]byte, error) {
+	hashers := make(map[Type]*Hasher, len(types))
+	writers := make([]io.Writer, 0, len(types))
+
+	defer func() {
+		for _, h := range hashers {
+			h.Release()
+		}
+	}()
+
+	for _, ht := range types {
+		if _, ok := hashers[ht]; ok {
+			continue
+		}
+		h, err := NewHasher(ht)
+		if err != nil {
+			return nil, err
+		}
+		hashers[ht] = h
+		writers = append(writers, h.Hash)
+	}
+
+	bp := copyBufPool.Get().(*[]byte)
+	_, err := io.CopyBuffer(io.MultiWriter(writers...), r, *bp)
+	copyBufPool.Put(bp)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[Type][]byte, len(hashers))
+	for ht, h := range hashers {
+		sums[ht] = h.Digest()
+	}
+	return sums, nil
+}
@@ -0,0 +1,156 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yunginnanet/common/entropy"
+)
+
+func TestSumReader(t *testing.T) {
+	t.Parallel()
+	for k, v := range valids {
+		typeToTest := k
+		valueToTest := v
+		t.Run(typeToTest.String(), func(t *testing.T) {
+			t.Parallel()
+			res, err := SumReader(typeToTest, bytes.NewReader(kayosByteSlice))
+			if err != nil {
+				t.Fatalf("[FAIL] %s: %s", typeToTest.String(), err.Error())
+			}
+			if !bytes.Equal(res, valueToTest) {
+				t.Errorf("[FAIL] %s: wanted %v, got %v", typeToTest.String(), valueToTest, res)
+			}
+		})
+	}
+	t.Run("unknown type", func(t *testing.T) {
+		t.Parallel()
+		if _, err := SumReader(TypeNull, strings.NewReader("yeet")); !errors.Is(err, ErrUnknownType) {
+			t.Fatalf("[FAIL] wanted ErrUnknownType, got %v", err)
+		}
+		if _, err := NewHasher(Type(94)); !errors.Is(err, ErrUnknownType) {
+			t.Fatalf("[FAIL] wanted ErrUnknownType, got %v", err)
+		}
+	})
+}
+
+func TestHasher(t *testing.T) {
+	t.Parallel()
+	dat := []byte(entropy.RandStrWithUpper(100000))
+	for k := range valids {
+		typeToTest := k
+		t.Run(typeToTest.String(), func(t *testing.T) {
+			t.Parallel()
+			h, err := NewHasher(typeToTest)
+			if err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			defer h.Release()
+			if h.Type() != typeToTest {
+				t.Errorf("[FAIL] wanted %s, got %s", typeToTest.String(), h.Type().String())
+			}
+			// write in uneven chunks to make sure state carries between writes
+			for i := 0; i < len(dat); i += 777 {
+				end := i + 777
+				if end > len(dat) {
+					end = len(dat)
+				}
+				if _, err = h.Write(dat[i:end]); err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+			}
+			if !bytes.Equal(h.Digest(), Sum(typeToTest, dat)) {
+				t.Errorf("[FAIL] %s: streamed digest does not match Sum", typeToTest.String())
+			}
+		})
+	}
+	t.Run("tee", func(t *testing.T) {
+		t.Parallel()
+		h, err := NewHasher(TypeSHA256)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		out, err := io.ReadAll(h.TeeReader(bytes.NewReader(dat)))
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		if !bytes.Equal(out, dat) {
+			t.Errorf("[FAIL] TeeReader altered the data passing through it")
+		}
+		if !bytes.Equal(h.Digest(), Sum(TypeSHA256, dat)) {
+			t.Errorf("[FAIL] TeeReader digest does not match Sum")
+		}
+		h.Release()
+		h.Release()
+	})
+}
+
+func BenchmarkSumReader(b *testing.B) {
+	dat := []byte(entropy.RandStrWithUpper(1024 * 1024))
+	for sumType := range valids {
+		b.Run(sumType.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(dat)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = SumReader(sumType, bytes.NewReader(dat))
+			}
+		})
+	}
+}
+
+func TestMultiSum(t *testing.T) {
+	t.Parallel()
+	types := make([]Type, 0, len(valids)+1)
+	for k := range valids {
+		types = append(types, k)
+	}
+	types = append(types, TypeSHA256) // duplicate
+
+	check := func(t *testing.T, sums map[Type][]byte) {
+		t.Helper()
+		if len(sums) != len(valids) {
+			t.Fatalf("[FAIL] wanted %d sums, got %d", len(valids), len(sums))
+		}
+		for k, v := range valids {
+			if !bytes.Equal(sums[k], v) {
+				t.Errorf("[FAIL] %s: wanted %v, got %v", k.String(), v, sums[k])
+			}
+		}
+	}
+
+	t.Run("reader", func(t *testing.T) {
+		t.Parallel()
+		sums, err := MultiSum(types, bytes.NewReader(kayosByteSlice))
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		check(t, sums)
+	})
+	t.Run("file", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "multi")
+		if err := os.WriteFile(path, kayosByteSlice, 0o600); err != nil {
+			t.Fatalf("[FAIL] failed to write test file: %s", err.Error())
+		}
+		sums, err := MultiSumFile(types, path)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		check(t, sums)
+		if _, err = MultiSumFile(types, entropy.RandStrWithUpper(50)); err == nil {
+			t.Fatal("[FAIL] MultiSumFile should have failed on a missing file")
+		}
+	})
+	t.Run("unknown type", func(t *testing.T) {
+		t.Parallel()
+		if _, err := MultiSum([]Type{TypeSHA1, TypeNull}, strings.NewReader("yeet")); !errors.Is(err, ErrUnknownType) {
+			t.Fatalf("[FAIL] wanted ErrUnknownType, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,260 @@
+package hash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/yunginnanet/common/xerrors"
+)
+
+// SymlinkMode controls how [SumTree] treats symbolic links.
+type SymlinkMode int8
+
+const (
+	// SymlinkHashTarget hashes the link's target path rather than what it points to. This is the default,
+	// and matches how version control systems record symlinks.
+	SymlinkHashTarget SymlinkMode = iota
+	// SymlinkSkip leaves symlinks out of the tree entirely.
+	SymlinkSkip
+	// SymlinkFollow hashes the contents and mode of the file a symlink points to.
+	// Links to directories are skipped rather than descended into, to avoid cycles.
+	SymlinkFollow
+)
+
+// TreeOptions configures [SumTree]. The zero value is usable.
+type TreeOptions struct {
+	// Workers is the number of files hashed concurrently. Defaults to runtime.GOMAXPROCS(0).
+	Workers int
+	// Symlinks selects how symbolic links are handled.
+	Symlinks SymlinkMode
+	// Include, if non-empty, limits the tree to files matching at least one of these globs.
+	// Globs use [path.Match] syntax and are matched against both the relative path and the base name.
+	Include []string
+	// Exclude removes files and directories matching any of these globs. Exclude wins over Include.
+	Exclude []string
+}
+
+// TreeEntry is a single file that contributed to a [TreeSum].
+type TreeEntry struct {
+	// Path is relative to the root of the tree, using forward slashes.
+	Path string
+	Mode fs.FileMode
+	Sum  []byte
+}
+
+// TreeSum is the result of [SumTree].
+type TreeSum struct {
+	Type  Type
+	Root  []byte
+	Files []TreeEntry
+}
+
+const (
+	merkleLeaf byte = 0x00
+	merkleNode byte = 0x01
+)
+
+// globMatch reports whether any of the patterns match the slash separated relative path or its base name.
+func globMatch(patterns []string, rel string) bool {
+	base := path.Base(rel)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func validateGlobs(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("bad glob %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+type treeJob struct {
+	idx  int
+	full string
+	link bool
+}
+
+func (opts TreeOptions) workers() int {
+	if opts.Workers > 0 {
+		return opts.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// SumTree walks root and hashes every file found with the given [Type] using a bounded pool of workers.
+//
+// The returned [TreeSum] contains every file's digest along with a Merkle root covering each file's
+// relative path, permission bits and content digest. Files are ordered by path before the root is computed,
+// so the root is deterministic regardless of walk order or worker scheduling.
+//
+// Any errors encountered are collected in an [xerrors.Errors] stack; if any occur no root is returned.
+func SumTree(ht Type, root string, opts TreeOptions) (*TreeSum, error) {
+	if typeToPool(ht) == nil {
+		if ht.Keyed() {
+			return nil, ErrKeyRequired
+		}
+		return nil, ErrUnknownType
+	}
+	if err := validateGlobs(opts.Include); err != nil {
+		return nil, err
+	}
+	if err := validateGlobs(opts.Exclude); err != nil {
+		return nil, err
+	}
+
+	errs := xerrors.NewErrors()
+	entries := make([]TreeEntry, 0)
+	jobs := make([]treeJob, 0)
+
+	walkErr := filepath.WalkDir(root, func(full string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs.Push(err)
+			return nil
+		}
+		if full == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, full)
+		if err != nil {
+			errs.Push(err)
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if globMatch(opts.Exclude, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		link := d.Type()&fs.ModeSymlink != 0
+		switch {
+		case link && opts.Symlinks == SymlinkSkip:
+			return nil
+		case !link && !d.Type().IsRegular():
+			return nil
+		case len(opts.Include) > 0 && !globMatch(opts.Include, rel):
+			return nil
+		}
+		var info fs.FileInfo
+		if link && opts.Symlinks == SymlinkFollow {
+			info, err = os.Stat(full)
+			link = false
+		} else {
+			info, err = d.Info()
+		}
+		if err != nil {
+			errs.Push(err)
+			return nil
+		}
+		if !link && !info.Mode().IsRegular() {
+			return nil
+		}
+		entries = append(entries, TreeEntry{Path: rel, Mode: info.Mode().Type() | info.Mode().Perm()})
+		jobs = append(jobs, treeJob{idx: len(entries) - 1, full: full, link: link})
+		return nil
+	})
+	if walkErr != nil {
+		errs.Push(walkErr)
+	}
+
+	work := make(chan treeJob)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < opts.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range work {
+				sum, err := sumTreeEntry(ht, j)
+				if err != nil {
+					errs.Push(fmt.Errorf("%s: %w", entries[j.idx].Path, err))
+					continue
+				}
+				entries[j.idx].Sum = sum
+			}
+		}()
+	}
+	for _, j := range jobs {
+		work <- j
+	}
+	close(work)
+	wg.Wait()
+
+	if errs.Len() > 0 {
+		return nil, errs
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	return &TreeSum{Type: ht, Root: merkleRoot(ht, entries), Files: entries}, nil
+}
+
+func sumTreeEntry(ht Type, j treeJob) ([]byte, error) {
+	if j.link {
+		target, err := os.Readlink(j.full)
+		if err != nil {
+			return nil, err
+		}
+		return Sum(ht, []byte(filepath.ToSlash(target))), nil
+	}
+	sums, err := MultiSumFile([]Type{ht}, j.full)
+	return sums[ht], err
+}
+
+func merkleLeafSum(ht Type, e TreeEntry) []byte {
+	buf := make([]byte, 0, 1+len(e.Path)+1+4+len(e.Sum))
+	buf = append(buf, merkleLeaf)
+	buf = append(buf, e.Path...)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(e.Mode))
+	buf = append(buf, e.Sum...)
+	return Sum(ht, buf)
+}
+
+// merkleRoot builds a binary Merkle tree over the (sorted) entries. Leaves and interior nodes are domain
+// separated with a prefix byte, and an odd node at any level is promoted to the next level unchanged.
+func merkleRoot(ht Type, entries []TreeEntry) []byte {
+	if len(entries) == 0 {
+		return Sum(ht, []byte{merkleNode})
+	}
+	level := make([][]byte, len(entries))
+	for i, e := range entries {
+		level[i] = merkleLeafSum(ht, e)
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			buf := make([]byte, 0, 1+len(level[i])+len(level[i+1]))
+			buf = append(buf, merkleNode)
+			buf = append(buf, level[i]...)
+			buf = append(buf, level[i+1]...)
+			next = append(next, Sum(ht, buf))
+		}
+		level = next
+	}
+	return level[0]
+}
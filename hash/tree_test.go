@@ -0,0 +1,173 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func writeTreeFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":          "kayos\n",
+		"b.log":          "yeet\n",
+		"sub/c.txt":      "hello\n",
+		"sub/deep/d.txt": "world\n",
+		"skip/e.txt":     "nope\n",
+	}
+	for name, dat := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		if err := os.WriteFile(full, []byte(dat), 0o644); err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+	}
+	return dir
+}
+
+func TestSumTree(t *testing.T) {
+	t.Parallel()
+	dir := writeTreeFixture(t)
+
+	base, err := SumTree(TypeSHA256, dir, TreeOptions{Workers: 1})
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if len(base.Files) != 5 {
+		t.Fatalf("[FAIL] wanted 5 files, got %d", len(base.Files))
+	}
+	for i := 1; i < len(base.Files); i++ {
+		if base.Files[i-1].Path >= base.Files[i].Path {
+			t.Fatalf("[FAIL] files are not sorted: %s >= %s", base.Files[i-1].Path, base.Files[i].Path)
+		}
+	}
+	if !bytes.Equal(base.Files[0].Sum, ogsha256) {
+		t.Errorf("[FAIL] a.txt: wanted %x, got %x", ogsha256, base.Files[0].Sum)
+	}
+
+	t.Run("deterministic", func(t *testing.T) {
+		for _, workers := range []int{0, 2, 16} {
+			again, err := SumTree(TypeSHA256, dir, TreeOptions{Workers: workers})
+			if err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			if !bytes.Equal(again.Root, base.Root) {
+				t.Errorf("[FAIL] root changed with %d workers", workers)
+			}
+		}
+	})
+
+	t.Run("sensitivity", func(t *testing.T) {
+		check := func(name string, mutate func(dir string) error) {
+			d := writeTreeFixture(t)
+			if err := mutate(d); err != nil {
+				t.Fatalf("[FAIL] %s: %s", name, err.Error())
+			}
+			ts, err := SumTree(TypeSHA256, d, TreeOptions{})
+			if err != nil {
+				t.Fatalf("[FAIL] %s: %s", name, err.Error())
+			}
+			if bytes.Equal(ts.Root, base.Root) {
+				t.Errorf("[FAIL] %s did not change the root", name)
+			}
+		}
+		check("content", func(d string) error {
+			return os.WriteFile(filepath.Join(d, "a.txt"), []byte("kayos!\n"), 0o644)
+		})
+		check("mode", func(d string) error {
+			return os.Chmod(filepath.Join(d, "a.txt"), 0o600)
+		})
+		check("rename", func(d string) error {
+			return os.Rename(filepath.Join(d, "a.txt"), filepath.Join(d, "z.txt"))
+		})
+		check("new file", func(d string) error {
+			return os.WriteFile(filepath.Join(d, "new"), nil, 0o644)
+		})
+	})
+
+	t.Run("globs", func(t *testing.T) {
+		ts, err := SumTree(TypeSHA256, dir, TreeOptions{Include: []string{"*.txt"}, Exclude: []string{"skip"}})
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		if len(ts.Files) != 3 {
+			t.Errorf("[FAIL] wanted 3 files, got %d: %v", len(ts.Files), ts.Files)
+		}
+		if _, err = SumTree(TypeSHA256, dir, TreeOptions{Include: []string{"["}}); err == nil {
+			t.Error("[FAIL] bad glob should have failed")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := SumTree(TypeNull, dir, TreeOptions{}); !errors.Is(err, ErrUnknownType) {
+			t.Errorf("[FAIL] wanted ErrUnknownType, got %v", err)
+		}
+		if _, err := SumTree(TypeHMACSHA256, dir, TreeOptions{}); !errors.Is(err, ErrKeyRequired) {
+			t.Errorf("[FAIL] wanted ErrKeyRequired, got %v", err)
+		}
+		if _, err := SumTree(TypeSHA256, filepath.Join(dir, "nope"), TreeOptions{}); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("[FAIL] wanted os.ErrNotExist, got %v", err)
+		}
+	})
+}
+
+func TestSumTreeSymlinks(t *testing.T) {
+	t.Parallel()
+	dir := writeTreeFixture(t)
+	if err := os.Symlink("a.txt", filepath.Join(dir, "link")); err != nil {
+		t.Skipf("symlinks unsupported: %s", err.Error())
+	}
+	if err := os.Symlink("sub", filepath.Join(dir, "dirlink")); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+
+	counts := map[SymlinkMode]int{SymlinkHashTarget: 7, SymlinkSkip: 5, SymlinkFollow: 6}
+	roots := make(map[string]SymlinkMode)
+	for mode, want := range counts {
+		ts, err := SumTree(TypeSHA256, dir, TreeOptions{Symlinks: mode})
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		if len(ts.Files) != want {
+			t.Errorf("[FAIL] mode %d: wanted %d files, got %d", mode, want, len(ts.Files))
+		}
+		if other, ok := roots[string(ts.Root)]; ok {
+			t.Errorf("[FAIL] modes %d and %d produced the same root", mode, other)
+		}
+		roots[string(ts.Root)] = mode
+		if mode != SymlinkFollow {
+			continue
+		}
+		for _, f := range ts.Files {
+			if f.Path == "link" && !bytes.Equal(f.Sum, ogsha256) {
+				t.Errorf("[FAIL] followed link: wanted %x, got %x", ogsha256, f.Sum)
+			}
+		}
+	}
+}
+
+func BenchmarkSumTree(b *testing.B) {
+	dir := b.TempDir()
+	dat := bytes.Repeat([]byte("kayos"), 1024*50)
+	for i := 0; i < 64; i++ {
+		if err := os.WriteFile(filepath.Join(dir, strconv.Itoa(i)), dat, 0o644); err != nil {
+			b.Fatalf("[FAIL] %s", err.Error())
+		}
+	}
+	for _, workers := range []int{1, 4, 0} {
+		b.Run("workers"+strconv.Itoa(workers), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(dat) * 64))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = SumTree(TypeSHA256, dir, TreeOptions{Workers: workers})
+			}
+		})
+	}
+}
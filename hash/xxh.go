@@ -0,0 +1,370 @@
+package hash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Pure Go implementations of xxHash64 and XXH3-64, seed 0, default secret.
+// See: https://github.com/Cyan4973/xxHash/blob/dev/doc/xxhash_spec.md
+
+const (
+	xxPrime32_1 uint64 = 0x9E3779B1
+	xxPrime32_2 uint64 = 0x85EBCA77
+	xxPrime32_3 uint64 = 0xC2B2AE3D
+
+	xxPrime64_1 uint64 = 0x9E3779B185EBCA87
+	xxPrime64_2 uint64 = 0xC2B2AE3D27D4EB4F
+	xxPrime64_3 uint64 = 0x165667B19E3779F9
+	xxPrime64_4 uint64 = 0x85EBCA77C2B2AE63
+	xxPrime64_5 uint64 = 0x27D4EB2F165667C5
+
+	xxPrimeMX1 uint64 = 0x165667919E3779F9
+	xxPrimeMX2 uint64 = 0x9FB21C651E98DF25
+
+	// initial xxHash64 accumulators for seed 0, wrapped: prime1+prime2 and -prime1.
+	xxh64V1 uint64 = 0x60EA27EEADC0B5D6
+	xxh64V4 uint64 = 0x61C8864E7A143579
+)
+
+func le64(b []byte) uint64 { return binary.LittleEndian.Uint64(b) }
+func le32(b []byte) uint64 { return uint64(binary.LittleEndian.Uint32(b)) }
+
+func xxh64Avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= xxPrime64_2
+	h ^= h >> 29
+	h *= xxPrime64_3
+	h ^= h >> 32
+	return h
+}
+
+// ---------------------------------------------------------------- xxHash64
+
+type xxh64 struct {
+	v     [4]uint64
+	total uint64
+	mem   [32]byte
+	n     int
+}
+
+func newXXH64() *xxh64 {
+	x := new(xxh64)
+	x.Reset()
+	return x
+}
+
+func xxh64Round(acc, input uint64) uint64 {
+	acc += input * xxPrime64_2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime64_1
+}
+
+func xxh64MergeRound(acc, val uint64) uint64 {
+	acc ^= xxh64Round(0, val)
+	return acc*xxPrime64_1 + xxPrime64_4
+}
+
+func (x *xxh64) Reset() {
+	x.v = [4]uint64{xxh64V1, xxPrime64_2, 0, xxh64V4}
+	x.total = 0
+	x.n = 0
+}
+
+func (x *xxh64) Size() int      { return 8 }
+func (x *xxh64) BlockSize() int { return 32 }
+
+func (x *xxh64) stripes(b []byte) []byte {
+	for ; len(b) >= 32; b = b[32:] {
+		x.v[0] = xxh64Round(x.v[0], le64(b[0:8]))
+		x.v[1] = xxh64Round(x.v[1], le64(b[8:16]))
+		x.v[2] = xxh64Round(x.v[2], le64(b[16:24]))
+		x.v[3] = xxh64Round(x.v[3], le64(b[24:32]))
+	}
+	return b
+}
+
+func (x *xxh64) Write(b []byte) (int, error) {
+	n := len(b)
+	x.total += uint64(n)
+	if x.n+n < 32 {
+		x.n += copy(x.mem[x.n:], b)
+		return n, nil
+	}
+	if x.n > 0 {
+		c := copy(x.mem[x.n:], b)
+		x.stripes(x.mem[:])
+		b = b[c:]
+		x.n = 0
+	}
+	b = x.stripes(b)
+	x.n = copy(x.mem[:], b)
+	return n, nil
+}
+
+func (x *xxh64) Sum64() uint64 {
+	var h uint64
+	if x.total >= 32 {
+		h = bits.RotateLeft64(x.v[0], 1) + bits.RotateLeft64(x.v[1], 7) +
+			bits.RotateLeft64(x.v[2], 12) + bits.RotateLeft64(x.v[3], 18)
+		for _, v := range x.v {
+			h = xxh64MergeRound(h, v)
+		}
+	} else {
+		h = xxPrime64_5
+	}
+	h += x.total
+
+	b := x.mem[:x.n]
+	for ; len(b) >= 8; b = b[8:] {
+		h ^= xxh64Round(0, le64(b))
+		h = bits.RotateLeft64(h, 27)*xxPrime64_1 + xxPrime64_4
+	}
+	if len(b) >= 4 {
+		h ^= le32(b) * xxPrime64_1
+		h = bits.RotateLeft64(h, 23)*xxPrime64_2 + xxPrime64_3
+		b = b[4:]
+	}
+	for _, c := range b {
+		h ^= uint64(c) * xxPrime64_5
+		h = bits.RotateLeft64(h, 11) * xxPrime64_1
+	}
+	return xxh64Avalanche(h)
+}
+
+func (x *xxh64) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, x.Sum64())
+}
+
+// ---------------------------------------------------------------- XXH3-64
+
+const (
+	xxh3StripeLen       = 64
+	xxh3SecretSize      = 192
+	xxh3StripesPerBlock = (xxh3SecretSize - xxh3StripeLen) / 8
+	xxh3BufferSize      = 256
+	xxh3MidSizeMax      = 240
+)
+
+var xxh3Secret = [xxh3SecretSize]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+func mulFold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= xxPrimeMX1
+	h ^= h >> 32
+	return h
+}
+
+func xxh3RRMXMX(h uint64, n int) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= xxPrimeMX2
+	h ^= (h >> 35) + uint64(n)
+	h *= xxPrimeMX2
+	h ^= h >> 28
+	return h
+}
+
+func xxh3Mix16(b, sec []byte) uint64 {
+	return mulFold64(le64(b)^le64(sec), le64(b[8:])^le64(sec[8:]))
+}
+
+// xxh3Short hashes inputs of at most xxh3MidSizeMax bytes.
+func xxh3Short(b []byte) uint64 {
+	sec := xxh3Secret[:]
+	n := len(b)
+	switch {
+	case n == 0:
+		return xxh64Avalanche(le64(sec[56:]) ^ le64(sec[64:]))
+	case n <= 3:
+		combined := uint64(b[0])<<16 | uint64(b[n>>1])<<24 | uint64(b[n-1]) | uint64(n)<<8
+		return xxh64Avalanche(combined ^ (le32(sec) ^ le32(sec[4:])))
+	case n <= 8:
+		input := le32(b[n-4:]) + le32(b)<<32
+		return xxh3RRMXMX(input^(le64(sec[8:])^le64(sec[16:])), n)
+	case n <= 16:
+		lo := le64(b) ^ (le64(sec[24:]) ^ le64(sec[32:]))
+		hi := le64(b[n-8:]) ^ (le64(sec[40:]) ^ le64(sec[48:]))
+		acc := uint64(n) + bits.ReverseBytes64(lo) + hi + mulFold64(lo, hi)
+		return xxh3Avalanche(acc)
+	case n <= 128:
+		acc := uint64(n) * xxPrime64_1
+		if n > 32 {
+			if n > 64 {
+				if n > 96 {
+					acc += xxh3Mix16(b[48:], sec[96:])
+					acc += xxh3Mix16(b[n-64:], sec[112:])
+				}
+				acc += xxh3Mix16(b[32:], sec[64:])
+				acc += xxh3Mix16(b[n-48:], sec[80:])
+			}
+			acc += xxh3Mix16(b[16:], sec[32:])
+			acc += xxh3Mix16(b[n-32:], sec[48:])
+		}
+		acc += xxh3Mix16(b, sec)
+		acc += xxh3Mix16(b[n-16:], sec[16:])
+		return xxh3Avalanche(acc)
+	default:
+		acc := uint64(n) * xxPrime64_1
+		for i := 0; i < 8; i++ {
+			acc += xxh3Mix16(b[16*i:], sec[16*i:])
+		}
+		acc = xxh3Avalanche(acc)
+		for i := 8; i < n/16; i++ {
+			acc += xxh3Mix16(b[16*i:], sec[16*(i-8)+3:])
+		}
+		acc += xxh3Mix16(b[n-16:], sec[136-17:])
+		return xxh3Avalanche(acc)
+	}
+}
+
+type xxh3Acc [8]uint64
+
+func (acc *xxh3Acc) accumulate512(b, sec []byte) {
+	for i := 0; i < 8; i++ {
+		val := le64(b[8*i:])
+		key := val ^ le64(sec[8*i:])
+		acc[i^1] += val
+		acc[i] += (key & 0xFFFFFFFF) * (key >> 32)
+	}
+}
+
+func (acc *xxh3Acc) scramble(sec []byte) {
+	for i := 0; i < 8; i++ {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= le64(sec[8*i:])
+		acc[i] = a * xxPrime32_1
+	}
+}
+
+func (acc *xxh3Acc) accumulate(b []byte, secOffset, stripes int) {
+	for s := 0; s < stripes; s++ {
+		acc.accumulate512(b[s*xxh3StripeLen:], xxh3Secret[secOffset+s*8:])
+	}
+}
+
+// consume feeds whole stripes into the accumulators, scrambling at block boundaries.
+func (acc *xxh3Acc) consume(soFar *int, b []byte, stripes int) {
+	if xxh3StripesPerBlock-*soFar <= stripes {
+		toEnd := xxh3StripesPerBlock - *soFar
+		acc.accumulate(b, *soFar*8, toEnd)
+		acc.scramble(xxh3Secret[xxh3SecretSize-xxh3StripeLen:])
+		acc.accumulate(b[toEnd*xxh3StripeLen:], 0, stripes-toEnd)
+		*soFar = stripes - toEnd
+		return
+	}
+	acc.accumulate(b, *soFar*8, stripes)
+	*soFar += stripes
+}
+
+func (acc *xxh3Acc) merge(total uint64) uint64 {
+	res := total * xxPrime64_1
+	for i := 0; i < 4; i++ {
+		sec := xxh3Secret[11+16*i:]
+		res += mulFold64(acc[2*i]^le64(sec), acc[2*i+1]^le64(sec[8:]))
+	}
+	return xxh3Avalanche(res)
+}
+
+type xxh3 struct {
+	acc   xxh3Acc
+	buf   [xxh3BufferSize]byte
+	n     int
+	soFar int
+	total uint64
+}
+
+func newXXH3() *xxh3 {
+	x := new(xxh3)
+	x.Reset()
+	return x
+}
+
+func (x *xxh3) Reset() {
+	x.acc = xxh3Acc{
+		xxPrime32_3, xxPrime64_1, xxPrime64_2, xxPrime64_3,
+		xxPrime64_4, xxPrime32_2, xxPrime64_5, xxPrime32_1,
+	}
+	x.n = 0
+	x.soFar = 0
+	x.total = 0
+}
+
+func (x *xxh3) Size() int      { return 8 }
+func (x *xxh3) BlockSize() int { return xxh3StripeLen }
+
+func (x *xxh3) Write(b []byte) (int, error) {
+	n := len(b)
+	x.total += uint64(n)
+	if x.n+n <= xxh3BufferSize {
+		x.n += copy(x.buf[x.n:], b)
+		return n, nil
+	}
+
+	const bufStripes = xxh3BufferSize / xxh3StripeLen
+
+	if x.n > 0 {
+		c := copy(x.buf[x.n:], b)
+		b = b[c:]
+		x.acc.consume(&x.soFar, x.buf[:], bufStripes)
+		x.n = 0
+	}
+
+	// always leave at least one byte buffered so that Sum has a final stripe to work with
+	if len(b) > xxh3BufferSize {
+		var consumed []byte
+		for len(b) > xxh3BufferSize {
+			x.acc.consume(&x.soFar, b, bufStripes)
+			consumed = b[:xxh3BufferSize]
+			b = b[xxh3BufferSize:]
+		}
+		// keep the last consumed stripe around in case fewer than a stripe's worth remains
+		copy(x.buf[xxh3BufferSize-xxh3StripeLen:], consumed[xxh3BufferSize-xxh3StripeLen:])
+	}
+
+	x.n = copy(x.buf[:], b)
+	return n, nil
+}
+
+func (x *xxh3) Sum64() uint64 {
+	if x.total <= xxh3MidSizeMax {
+		return xxh3Short(x.buf[:x.total])
+	}
+
+	acc := x.acc
+	soFar := x.soFar
+	var last [xxh3StripeLen]byte
+	if x.n >= xxh3StripeLen {
+		stripes := (x.n - 1) / xxh3StripeLen
+		acc.consume(&soFar, x.buf[:], stripes)
+		copy(last[:], x.buf[x.n-xxh3StripeLen:x.n])
+	} else {
+		catchup := xxh3StripeLen - x.n
+		copy(last[:], x.buf[xxh3BufferSize-catchup:])
+		copy(last[catchup:], x.buf[:x.n])
+	}
+	acc.accumulate512(last[:], xxh3Secret[xxh3SecretSize-xxh3StripeLen-7:])
+	return acc.merge(x.total)
+}
+
+func (x *xxh3) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, x.Sum64())
+}
@@ -0,0 +1,50 @@
+package hash
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/yunginnanet/common/entropy"
+)
+
+func TestXXHEmpty(t *testing.T) {
+	t.Parallel()
+	// reference values from the xxHash project
+	if got := hex.EncodeToString(Sum(TypeXXH64, nil)); got != "ef46db3751d8e999" {
+		t.Errorf("[FAIL] xxh64: wanted ef46db3751d8e999, got %s", got)
+	}
+	if got := hex.EncodeToString(Sum(TypeXXH3, nil)); got != "2d06800538d394c2" {
+		t.Errorf("[FAIL] xxh3: wanted 2d06800538d394c2, got %s", got)
+	}
+}
+
+// TestXXHStreaming makes sure that the streaming state machines agree with themselves no matter how the
+// input is split up, across every size class of XXH3 (short, mid, and long with partial/whole blocks).
+func TestXXHStreaming(t *testing.T) {
+	t.Parallel()
+	sizes := []int{1, 3, 4, 8, 9, 16, 17, 128, 129, 240, 241, 255, 256, 257, 1023, 1024, 1025, 4096, 100000}
+	chunks := []int{1, 7, 63, 64, 65, 256, 1000}
+	for _, ht := range []Type{TypeXXH64, TypeXXH3} {
+		for _, size := range sizes {
+			dat := []byte(entropy.RandStrWithUpper(size))
+			want := Sum(ht, dat)
+			for _, chunk := range chunks {
+				h, err := NewHasher(ht)
+				if err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+				for i := 0; i < len(dat); i += chunk {
+					end := i + chunk
+					if end > len(dat) {
+						end = len(dat)
+					}
+					_, _ = h.Write(dat[i:end])
+				}
+				if got := h.Digest(); hex.EncodeToString(got) != hex.EncodeToString(want) {
+					t.Errorf("[FAIL] %s size %d chunk %d: wanted %x, got %x", ht.String(), size, chunk, want, got)
+				}
+				h.Release()
+			}
+		}
+	}
+}
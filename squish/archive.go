@@ -0,0 +1,417 @@
+package squish
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrUnsafePath is returned when unpacking an archive entry that would end up outside of the destination,
+// through `..` traversal, an absolute path or a symlink.
+var ErrUnsafePath = errors.New("squish: unsafe path in archive")
+
+// maxLinkDepth bounds how many symlinks are followed when resolving a link target during unpacking.
+const maxLinkDepth = 40
+
+// TarOptions controls packing and unpacking archives. The zero value imposes no limits.
+type TarOptions struct {
+	// SkipSymlinks omits symlinks when packing, and ignores symlink entries when unpacking.
+	SkipSymlinks bool
+	// MaxEntries is the maximum number of entries to unpack. Zero means unlimited.
+	MaxEntries int
+	// MaxSize is the maximum total size of the regular files to unpack. Zero means unlimited.
+	MaxSize int64
+	// Limits are applied to gzip decompression by [UntarGz].
+	Limits Limits
+}
+
+func firstTarOptions(opts []TarOptions) TarOptions {
+	if len(opts) == 0 {
+		return TarOptions{}
+	}
+	return opts[0]
+}
+
+// Tar writes a tar archive of the contents of dir to w, with paths relative to dir.
+// Regular files, directories and symlinks (which are stored, not followed) are archived along with
+// their permission bits and modification times, truncated to the second. Other file types are skipped.
+func Tar(dir string, w io.Writer, opts ...TarOptions) error {
+	opt := firstTarOptions(opts)
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		switch typ := info.Mode().Type(); {
+		case typ == fs.ModeSymlink:
+			if opt.SkipSymlinks {
+				return nil
+			}
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		case typ != 0 && typ != fs.ModeDir:
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		hdr.ModTime = info.ModTime().Truncate(time.Second)
+		hdr.AccessTime, hdr.ChangeTime = time.Time{}, time.Time{}
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// TarGz is [Tar] compressed with pooled gzip at the default level.
+func TarGz(dir string, w io.Writer, opts ...TarOptions) error {
+	gz, err := NewWriter(w, gzip.DefaultCompression)
+	if err != nil {
+		return err
+	}
+	if err = Tar(dir, gz, opts...); err != nil {
+		_ = gz.Close()
+		return err
+	}
+	return gz.Close()
+}
+
+// UntarGz is [Untar] for a gzip compressed archive, with opts.Limits applied to decompression.
+func UntarGz(r io.Reader, dest string, opts TarOptions) error {
+	gz, err := NewReader(r, opts.Limits)
+	if err != nil {
+		return err
+	}
+	err = Untar(gz, dest, opts)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Untar unpacks a tar archive from r into dest, creating dest if needed.
+//
+// Every entry must stay inside dest: entries with absolute paths or `..` components, symlinks whose
+// target resolves outside of dest (following any symlinks already unpacked), and entries that would be
+// written through or replace a symlink are all rejected with [ErrUnsafePath]. Regular files, directories,
+// hard links and symlinks are unpacked; other types are skipped. Permission bits and modification times
+// are restored, except for symlink modification times. Exceeding opts.MaxEntries or opts.MaxSize
+// returns [ErrLimitExceeded].
+//
+// Unpacking stops at the first error, leaving whatever was unpacked so far in place, except for symlinks
+// that a later entry made resolve outside of dest, which are removed.
+func Untar(r io.Reader, dest string, opts TarOptions) error {
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+
+	dirs, links, err := untar(tar.NewReader(r), dest, opts)
+	// a symlink is checked when it is unpacked, but a later entry can still change what it resolves to,
+	// e.g. `l -> s/..` followed by `s -> .`, so all of them are checked again however unpacking ended.
+	if linkErr := recheckLinks(dest, links); err == nil {
+		err = linkErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// directory modes and times are applied last, so that read-only directories can be filled
+	// and adding entries doesn't bump their modification times.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target := filepath.Join(dest, filepath.FromSlash(strings.TrimSuffix(dirs[i].Name, "/")))
+		if err = os.Chmod(target, dirs[i].FileInfo().Mode().Perm()); err != nil {
+			return err
+		}
+		if err = os.Chtimes(target, dirs[i].ModTime, dirs[i].ModTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// untar does the unpacking for Untar, returning the directories and symlinks (relative to dest) it
+// unpacked along with the first error, if any.
+func untar(tr *tar.Reader, dest string, opts TarOptions) ([]*tar.Header, []string, error) {
+	var (
+		dirs  []*tar.Header
+		links []string
+	)
+	entries, total := 0, int64(0)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return dirs, links, nil
+		}
+		if err != nil {
+			return dirs, links, err
+		}
+		if entries++; opts.MaxEntries > 0 && entries > opts.MaxEntries {
+			return dirs, links, fmt.Errorf("%w: more than %d entries", ErrLimitExceeded, opts.MaxEntries)
+		}
+
+		name := filepath.FromSlash(strings.TrimSuffix(hdr.Name, "/"))
+		if !filepath.IsLocal(name) {
+			return dirs, links, fmt.Errorf("%w: %s", ErrUnsafePath, hdr.Name)
+		}
+		if err = checkParents(dest, name); err != nil {
+			return dirs, links, err
+		}
+		target := filepath.Join(dest, name)
+		mode := hdr.FileInfo().Mode().Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = mkdirNoFollow(target); err != nil {
+				return dirs, links, err
+			}
+			dirs = append(dirs, hdr)
+		case tar.TypeReg:
+			if total += hdr.Size; opts.MaxSize > 0 && total > opts.MaxSize {
+				return dirs, links, fmt.Errorf("%w: more than %d bytes", ErrLimitExceeded, opts.MaxSize)
+			}
+			if err = writeFile(target, tr, mode, hdr.ModTime); err != nil {
+				return dirs, links, err
+			}
+		case tar.TypeSymlink:
+			if opts.SkipSymlinks {
+				continue
+			}
+			if err = checkLink(dest, name, hdr.Linkname); err != nil {
+				return dirs, links, err
+			}
+			if existing, _ := os.Readlink(target); existing == hdr.Linkname {
+				links = append(links, name)
+				continue
+			}
+			if err = replaceable(target); err != nil {
+				return dirs, links, err
+			}
+			if err = os.Symlink(hdr.Linkname, target); err != nil {
+				return dirs, links, err
+			}
+			links = append(links, name)
+		case tar.TypeLink:
+			src := filepath.FromSlash(hdr.Linkname)
+			if !filepath.IsLocal(src) {
+				return dirs, links, fmt.Errorf("%w: %s links to %s", ErrUnsafePath, hdr.Name, hdr.Linkname)
+			}
+			if err = checkParents(dest, src); err != nil {
+				return dirs, links, err
+			}
+			if fi, err := os.Lstat(filepath.Join(dest, src)); err != nil || !fi.Mode().IsRegular() {
+				return dirs, links, fmt.Errorf("%w: %s links to %s, which is not a regular file", ErrUnsafePath, hdr.Name, hdr.Linkname)
+			}
+			if err = replaceable(target); err != nil {
+				return dirs, links, err
+			}
+			if err = os.Link(filepath.Join(dest, src), target); err != nil {
+				return dirs, links, err
+			}
+		}
+	}
+}
+
+// recheckLinks checks every symlink in links (relative to dest) again, removing any that now resolve
+// outside of dest. It returns the first such error.
+func recheckLinks(dest string, links []string) error {
+	var first error
+	for _, name := range links {
+		link, err := os.Readlink(filepath.Join(dest, name))
+		if err != nil {
+			continue
+		}
+		if err = checkLink(dest, name, link); err == nil {
+			continue
+		}
+		if rmErr := os.Remove(filepath.Join(dest, name)); rmErr != nil {
+			err = errors.Join(err, rmErr)
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// checkParents makes sure that none of the existing parent directories of name (relative to dest)
+// are symlinks, so that nothing is ever written through one.
+func checkParents(dest, name string) error {
+	dir := dest
+	parts := strings.Split(filepath.Dir(name), string(filepath.Separator))
+	for _, part := range parts {
+		if part == "." {
+			continue
+		}
+		dir = filepath.Join(dir, part)
+		fi, err := os.Lstat(dir)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil
+		case err != nil:
+			return err
+		case fi.Mode().Type() == fs.ModeSymlink:
+			return fmt.Errorf("%w: %s is inside symlink %s", ErrUnsafePath, name, dir)
+		case !fi.IsDir():
+			return fmt.Errorf("%w: %s is inside non-directory %s", ErrUnsafePath, name, dir)
+		}
+	}
+	return nil
+}
+
+// replaceable removes an existing regular file at target so that it can be replaced.
+// Existing symlinks are never replaced, as other already validated links may resolve through them.
+func replaceable(target string) error {
+	fi, err := os.Lstat(target)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil
+	case err != nil:
+		return err
+	case fi.Mode().IsRegular():
+		return os.Remove(target)
+	default:
+		return fmt.Errorf("%w: refusing to replace %s", ErrUnsafePath, target)
+	}
+}
+
+func mkdirNoFollow(target string) error {
+	fi, err := os.Lstat(target)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return os.MkdirAll(target, 0o700)
+	case err != nil:
+		return err
+	case !fi.IsDir():
+		return fmt.Errorf("%w: refusing to replace %s with a directory", ErrUnsafePath, target)
+	default:
+		// make sure it can be filled even if it was previously unpacked read-only.
+		return os.Chmod(target, 0o700)
+	}
+}
+
+func writeFile(target string, r io.Reader, mode fs.FileMode, mtime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := replaceable(target); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// the mode passed to OpenFile is subject to the umask
+		err = os.Chmod(target, mode)
+	}
+	if err == nil {
+		err = os.Chtimes(target, mtime, mtime)
+	}
+	return err
+}
+
+// checkLink makes sure that the symlink at name (relative to dest) pointing to link resolves inside of
+// dest, following any symlinks that have already been unpacked along the way.
+func checkLink(dest, name, link string) error {
+	if filepath.IsAbs(link) || filepath.VolumeName(link) != "" {
+		return fmt.Errorf("%w: %s links to absolute path %s", ErrUnsafePath, name, link)
+	}
+	// not filepath.Join, which would clean away `..` after components that may turn out to be symlinks.
+	rel := filepath.Dir(name) + string(filepath.Separator) + filepath.FromSlash(link)
+	if _, err := resolveIn(dest, rel, 0); err != nil {
+		return fmt.Errorf("%w: %s links to %s", err, name, link)
+	}
+	return nil
+}
+
+// resolveIn resolves the relative path rel inside of root like the OS would, following symlinks,
+// and returns the resolved path relative to root. It fails with [ErrUnsafePath] if the path ever
+// leaves root. Components that don't exist yet are resolved lexically.
+func resolveIn(root, rel string, depth int) (string, error) {
+	if depth > maxLinkDepth {
+		return "", fmt.Errorf("%w: too many levels of symlinks", ErrUnsafePath)
+	}
+	var resolved []string
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) == 0 {
+				return "", ErrUnsafePath
+			}
+			resolved = resolved[:len(resolved)-1]
+			continue
+		}
+		cur := filepath.Join(resolved...)
+		p := filepath.Join(root, cur, part)
+		fi, err := os.Lstat(p)
+		if err != nil || fi.Mode().Type() != fs.ModeSymlink {
+			resolved = append(resolved, part)
+			continue
+		}
+		link, err := os.Readlink(p)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(link) {
+			return "", ErrUnsafePath
+		}
+		target, err := resolveIn(root, cur+string(filepath.Separator)+link, depth+1)
+		if err != nil {
+			return "", err
+		}
+		resolved = strings.Split(target, string(filepath.Separator))
+		if target == "" {
+			resolved = nil
+		}
+	}
+	return filepath.Join(resolved...), nil
+}
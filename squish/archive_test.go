@@ -0,0 +1,271 @@
+package squish
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func makeTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	mtime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	files := map[string]fs.FileMode{"lorem.txt": 0o644, "bin/run.sh": 0o755, "bin/sub/secret": 0o600, "ro": 0o444}
+	for name, mode := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(lip+name), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(p, mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../lorem.txt", filepath.Join(root, "bin", "lorem")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "bin", "sub"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filepath.Join(root, "bin"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestTarGzRoundTrip(t *testing.T) {
+	src := makeTree(t)
+	buf := new(bytes.Buffer)
+	if err := TarGz(src, buf); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	dest := filepath.Join(t.TempDir(), "out")
+	if err := UntarGz(bytes.NewReader(buf.Bytes()), dest, TarOptions{MaxEntries: 100, MaxSize: 1 << 20}); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, p)
+		want, _ := os.Lstat(p)
+		got, err := os.Lstat(filepath.Join(dest, rel))
+		if err != nil {
+			t.Errorf("[FAIL] %s was not unpacked: %s", rel, err.Error())
+			return nil
+		}
+		if got.Mode() != want.Mode() {
+			t.Errorf("[FAIL] %s: wanted mode %s, got %s", rel, want.Mode(), got.Mode())
+		}
+		switch {
+		case want.Mode().Type() == fs.ModeSymlink:
+			wl, _ := os.Readlink(p)
+			gl, _ := os.Readlink(filepath.Join(dest, rel))
+			if wl != gl {
+				t.Errorf("[FAIL] %s: wanted link to %s, got %s", rel, wl, gl)
+			}
+		case rel == ".":
+		case !got.ModTime().Equal(want.ModTime().Truncate(time.Second)):
+			t.Errorf("[FAIL] %s: wanted mtime %s, got %s", rel, want.ModTime(), got.ModTime())
+		}
+		if want.Mode().IsRegular() {
+			wb, _ := os.ReadFile(p)
+			gb, _ := os.ReadFile(filepath.Join(dest, rel))
+			if !bytes.Equal(wb, gb) {
+				t.Errorf("[FAIL] %s: content mismatch", rel)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+
+	// unpacking over an existing tree replaces regular files
+	if err = UntarGz(bytes.NewReader(buf.Bytes()), dest, TarOptions{}); err != nil {
+		t.Errorf("[FAIL] unpacking twice: %s", err.Error())
+	}
+}
+
+func TestTarSkipSymlinks(t *testing.T) {
+	src := makeTree(t)
+	buf := new(bytes.Buffer)
+	if err := Tar(src, buf, TarOptions{SkipSymlinks: true}); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	tr := tar.NewReader(buf)
+	for hdr, err := tr.Next(); err == nil; hdr, err = tr.Next() {
+		if hdr.Typeflag == tar.TypeSymlink {
+			t.Errorf("[FAIL] %s: symlinks should have been skipped", hdr.Name)
+		}
+	}
+
+	buf.Reset()
+	_ = Tar(src, buf)
+	dest := t.TempDir()
+	if err := Untar(buf, dest, TarOptions{SkipSymlinks: true}); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "bin", "lorem")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("[FAIL] symlink should not have been unpacked: %v", err)
+	}
+}
+
+type entry struct {
+	name, link string
+	typ        byte
+	body       string
+}
+
+func tarOf(t *testing.T, entries ...entry) *bytes.Buffer {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Linkname: e.link, Typeflag: e.typ, Mode: 0o644, Size: int64(len(e.body))}
+		if e.typ == tar.TypeDir {
+			hdr.Mode = 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		_, _ = tw.Write([]byte(e.body))
+	}
+	_ = tw.Close()
+	return buf
+}
+
+func TestUntarUnsafe(t *testing.T) {
+	cases := map[string][]entry{
+		"dotdot":            {{name: "../evil", typ: tar.TypeReg, body: "yeet"}},
+		"nested dotdot":     {{name: "a/../../evil", typ: tar.TypeReg, body: "yeet"}},
+		"absolute":          {{name: "/tmp/evil", typ: tar.TypeReg, body: "yeet"}},
+		"absolute symlink":  {{name: "link", link: "/etc", typ: tar.TypeSymlink}},
+		"escaping symlink":  {{name: "a/link", link: "../../etc", typ: tar.TypeSymlink}},
+		"escaping hardlink": {{name: "hard", link: "../etc/passwd", typ: tar.TypeLink}},
+		"write through symlink": {
+			{name: "sub/", typ: tar.TypeDir},
+			{name: "link", link: "sub", typ: tar.TypeSymlink},
+			{name: "link/evil", typ: tar.TypeReg, body: "yeet"},
+		},
+		"chained symlinks": {
+			{name: "b", link: ".", typ: tar.TypeSymlink},
+			{name: "a", link: "b/..", typ: tar.TypeSymlink},
+		},
+		"symlink loop": {
+			{name: "x", link: "y", typ: tar.TypeSymlink},
+			{name: "y", link: "x", typ: tar.TypeSymlink},
+			{name: "z", link: "x/..", typ: tar.TypeSymlink},
+		},
+		"replace symlink": {
+			{name: "sub/", typ: tar.TypeDir},
+			{name: "x", link: "sub", typ: tar.TypeSymlink},
+			{name: "x", typ: tar.TypeReg, body: "yeet"},
+		},
+		"replace directory": {
+			{name: "sub/", typ: tar.TypeDir},
+			{name: "sub", link: ".", typ: tar.TypeSymlink},
+		},
+		"symlink through a later symlink": {
+			{name: "l", link: "s/..", typ: tar.TypeSymlink},
+			{name: "s", link: ".", typ: tar.TypeSymlink},
+		},
+		"symlink through a later symlink, then an error": {
+			{name: "l", link: "s/..", typ: tar.TypeSymlink},
+			{name: "s", link: ".", typ: tar.TypeSymlink},
+			{name: "../evil", typ: tar.TypeReg, body: "yeet"},
+		},
+		"symlink through a replaced file": {
+			{name: "f", typ: tar.TypeReg, body: "yeet"},
+			{name: "l", link: "f/..", typ: tar.TypeSymlink},
+			{name: "f", link: ".", typ: tar.TypeSymlink},
+		},
+	}
+	for name, entries := range cases {
+		dest := filepath.Join(t.TempDir(), "dest")
+		err := Untar(tarOf(t, entries...), dest, TarOptions{})
+		if !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("[FAIL] %s: wanted ErrUnsafePath, got %v", name, err)
+			continue
+		}
+		t.Logf("[PASS] %s: %s", name, err.Error())
+		if _, err = os.Stat(filepath.Join(filepath.Dir(dest), "evil")); err == nil {
+			t.Errorf("[FAIL] %s: file was written outside of dest", name)
+		}
+		checkLinksInside(t, name, dest)
+	}
+
+	dest := t.TempDir()
+	err := Untar(tarOf(t,
+		entry{name: "sub/deeper/", typ: tar.TypeDir},
+		entry{name: "sub/deeper/up", link: "../..", typ: tar.TypeSymlink},
+		entry{name: "sub/file", typ: tar.TypeReg, body: "yeet"},
+		entry{name: "sub/hard", link: "sub/file", typ: tar.TypeLink},
+		entry{name: "sub/deeper/file", link: "../file", typ: tar.TypeSymlink},
+		entry{name: "sub/deeper/later", link: "../later/file", typ: tar.TypeSymlink},
+		entry{name: "sub/later/file", typ: tar.TypeReg, body: "yeet"},
+	), dest, TarOptions{})
+	if err != nil {
+		t.Errorf("[FAIL] symlinks and hard links within dest should be allowed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "sub", "deeper", "later")); err != nil || string(data) != "yeet" {
+		t.Errorf("[FAIL] a symlink to an entry unpacked after it should work: %v", err)
+	}
+	checkLinksInside(t, "within dest", dest)
+}
+
+// checkLinksInside fails if any symlink left in dest resolves to somewhere outside of it.
+func checkLinksInside(t *testing.T, name, dest string) {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return
+	}
+	_ = filepath.WalkDir(dest, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.Type() != fs.ModeSymlink {
+			return nil
+		}
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(rel) && rel != "." {
+			t.Errorf("[FAIL] %s: %s resolves outside of dest, to %s", name, path, resolved)
+		}
+		return nil
+	})
+}
+
+func TestUntarLimits(t *testing.T) {
+	entries := make([]entry, 0, 10)
+	for i := 0; i < 10; i++ {
+		entries = append(entries, entry{name: "f" + strings.Repeat("x", i), typ: tar.TypeReg, body: lip})
+	}
+	if err := Untar(tarOf(t, entries...), t.TempDir(), TarOptions{MaxEntries: 9}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] MaxEntries: wanted ErrLimitExceeded, got %v", err)
+	}
+	if err := Untar(tarOf(t, entries...), t.TempDir(), TarOptions{MaxSize: int64(9 * len(lip))}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] MaxSize: wanted ErrLimitExceeded, got %v", err)
+	}
+	if err := Untar(tarOf(t, entries...), t.TempDir(), TarOptions{MaxEntries: 10, MaxSize: int64(10 * len(lip))}); err != nil {
+		t.Errorf("[FAIL] archive within limits: %v", err)
+	}
+
+	bomb := tarOf(t, entry{name: "zeros", typ: tar.TypeReg, body: string(make([]byte, bombSize))})
+	gzd := bytes.NewReader(Gzip(bomb.Bytes()))
+	if err := UntarGz(gzd, t.TempDir(), TarOptions{Limits: Limits{MaxOutput: 1 << 20}}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] Limits: wanted ErrLimitExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,304 @@
+package squish
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Names of the built-in codecs.
+const (
+	CodecGzip  = "gzip"
+	CodecZlib  = "zlib"
+	CodecFlate = "flate"
+	CodecLZW   = "lzw"
+)
+
+var (
+	// ErrUnknownCodec is returned when looking up a codec name that has not been registered.
+	ErrUnknownCodec = errors.New("squish: unknown codec")
+	// ErrCodecExists is returned when registering a codec under a name that is already taken.
+	ErrCodecExists = errors.New("squish: codec already registered")
+)
+
+// Codec is a named, streaming compression format.
+//
+// Writers returned by NewWriter must write any trailing data on Close, and neither the writers nor the
+// readers returned by a Codec should close the underlying stream they wrap.
+type Codec interface {
+	Name() string
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	codecsMu = &sync.RWMutex{}
+	codecs   = map[string]Codec{
+		CodecGzip:  gzipCodec{},
+		CodecZlib:  zlibCodec{},
+		CodecFlate: flateCodec{},
+		CodecLZW:   lzwCodec{},
+	}
+)
+
+// Register adds a [Codec] to the registry under its name.
+// It returns [ErrCodecExists] if the name is already in use.
+func Register(c Codec) error {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if _, ok := codecs[c.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrCodecExists, c.Name())
+	}
+	codecs[c.Name()] = c
+	return nil
+}
+
+// Lookup returns the registered [Codec] with the given name.
+func Lookup(name string) (Codec, error) {
+	codecsMu.RLock()
+	c, ok := codecs[name]
+	codecsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+	}
+	return c, nil
+}
+
+// Codecs returns the names of every registered codec in sorted order.
+func Codecs() []string {
+	codecsMu.RLock()
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	codecsMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Compress compresses data with the named codec.
+func Compress(codec string, data []byte) ([]byte, error) {
+	c, err := Lookup(codec)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	w, err := c.NewWriter(buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res, nil
+}
+
+// Decompress decompresses data with the named codec.
+// If [Limits] are given, decompression stops with [ErrLimitExceeded] as soon as one is crossed.
+func Decompress(codec string, data []byte, limits ...Limits) ([]byte, error) {
+	c, err := Lookup(codec)
+	if err != nil {
+		return nil, err
+	}
+	in := &countingReader{r: bytes.NewReader(data)}
+	r, err := c.NewReader(in)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	_, err = buf.ReadFrom(limitReader(r, in, firstLimits(limits)))
+	if closeErr := r.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res, nil
+}
+
+// pooledWriter returns its compressor to a pool when closed.
+type pooledWriter struct {
+	w     io.WriteCloser
+	reset func(io.Writer)
+	pool  *sync.Pool
+}
+
+func (p *pooledWriter) Write(b []byte) (int, error) {
+	if p.w == nil {
+		return 0, ErrClosed
+	}
+	return p.w.Write(b)
+}
+
+func (p *pooledWriter) Close() error {
+	if p.w == nil {
+		return ErrClosed
+	}
+	err := p.w.Close()
+	p.reset(nil)
+	p.pool.Put(p.w)
+	p.w = nil
+	return err
+}
+
+// pooledReader returns its decompressor to a pool when closed.
+type pooledReader struct {
+	r    io.ReadCloser
+	pool *sync.Pool
+}
+
+func (p *pooledReader) Read(b []byte) (int, error) {
+	if p.r == nil {
+		return 0, ErrClosed
+	}
+	return p.r.Read(b)
+}
+
+func (p *pooledReader) Close() error {
+	if p.r == nil {
+		return ErrClosed
+	}
+	err := p.r.Close()
+	p.pool.Put(p.r)
+	p.r = nil
+	return err
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string { return CodecGzip }
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return NewWriter(w, gzip.DefaultCompression)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return NewReader(r)
+}
+
+var (
+	zlibWriterPool = &sync.Pool{
+		New: func() interface{} {
+			return zlib.NewWriter(nil)
+		},
+	}
+	// zlib readers can't be created without a valid stream, so this pool has no New func.
+	zlibReaderPool = &sync.Pool{}
+)
+
+type zlibCodec struct{}
+
+func (zlibCodec) Name() string { return CodecZlib }
+
+func (zlibCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	zw := zlibWriterPool.Get().(*zlib.Writer)
+	zw.Reset(w)
+	return &pooledWriter{w: zw, reset: zw.Reset, pool: zlibWriterPool}, nil
+}
+
+func (zlibCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	if v := zlibReaderPool.Get(); v != nil {
+		zr := v.(io.ReadCloser)
+		if err := zr.(zlib.Resetter).Reset(r, nil); err != nil {
+			zlibReaderPool.Put(zr)
+			return nil, err
+		}
+		return &pooledReader{r: zr, pool: zlibReaderPool}, nil
+	}
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledReader{r: zr, pool: zlibReaderPool}, nil
+}
+
+var (
+	flateWriterPool = &sync.Pool{
+		New: func() interface{} {
+			fw, _ := flate.NewWriter(nil, flate.DefaultCompression)
+			return fw
+		},
+	}
+	flateReaderPool = &sync.Pool{
+		New: func() interface{} {
+			return flate.NewReader(nil)
+		},
+	}
+)
+
+type flateCodec struct{}
+
+func (flateCodec) Name() string { return CodecFlate }
+
+func (flateCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	fw := flateWriterPool.Get().(*flate.Writer)
+	fw.Reset(w)
+	return &pooledWriter{w: fw, reset: fw.Reset, pool: flateWriterPool}, nil
+}
+
+func (flateCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	fr := flateReaderPool.Get().(io.ReadCloser)
+	if err := fr.(flate.Resetter).Reset(r, nil); err != nil {
+		flateReaderPool.Put(fr)
+		return nil, err
+	}
+	return &pooledReader{r: fr, pool: flateReaderPool}, nil
+}
+
+// LZW parameters used by the lzw codec: LSB bit order with 8 bit literals, as used by GIF.
+const (
+	lzwOrder    = lzw.LSB
+	lzwLitWidth = 8
+)
+
+var (
+	lzwWriterPool = &sync.Pool{
+		New: func() interface{} {
+			return lzw.NewWriter(nil, lzwOrder, lzwLitWidth)
+		},
+	}
+	lzwReaderPool = &sync.Pool{
+		New: func() interface{} {
+			return lzw.NewReader(nil, lzwOrder, lzwLitWidth)
+		},
+	}
+)
+
+type lzwCodec struct{}
+
+func (lzwCodec) Name() string { return CodecLZW }
+
+func (lzwCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	lw := lzwWriterPool.Get().(*lzw.Writer)
+	lw.Reset(w, lzwOrder, lzwLitWidth)
+	return &pooledWriter{
+		w:     lw,
+		reset: func(w io.Writer) { lw.Reset(w, lzwOrder, lzwLitWidth) },
+		pool:  lzwWriterPool,
+	}, nil
+}
+
+func (lzwCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	lr := lzwReaderPool.Get().(*lzw.Reader)
+	lr.Reset(r, lzwOrder, lzwLitWidth)
+	return &pooledReader{r: lr, pool: lzwReaderPool}, nil
+}
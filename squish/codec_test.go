@@ -0,0 +1,92 @@
+package squish
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// identityCodec is a third-party codec that does no compression at all.
+type identityCodec struct{}
+
+func (identityCodec) Name() string { return "identity" }
+
+func (identityCodec) NewWriter(w io.Writer) (io.WriteCloser, error) { return nopWriteCloser{w}, nil }
+
+func (identityCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }
+
+func TestCodecs(t *testing.T) {
+	for _, name := range []string{CodecGzip, CodecZlib, CodecFlate, CodecLZW} {
+		codec := name
+		t.Run(codec, func(t *testing.T) {
+			// a few times over so that pooled instances get reused
+			for i := 0; i < 3; i++ {
+				packed, err := Compress(codec, []byte(lip))
+				if err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+				if len(packed) >= len(lip) {
+					t.Errorf("[FAIL] %s did not compress: %d >= %d", codec, len(packed), len(lip))
+				}
+				unpacked, err := Decompress(codec, packed)
+				if err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+				if string(unpacked) != lip {
+					t.Fatalf("[FAIL] %s round trip mismatch", codec)
+				}
+			}
+			if _, err := Decompress(codec, []byte("\xff\xff\xff\xff junk")); err == nil {
+				t.Errorf("[FAIL] %s should have failed on junk input", codec)
+			}
+			c, _ := Lookup(codec)
+			w, _ := c.NewWriter(io.Discard)
+			_ = w.Close()
+			if err := w.Close(); !errors.Is(err, ErrClosed) {
+				t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+			}
+		})
+	}
+
+	gz, err := Compress(CodecGzip, []byte(lip))
+	if err != nil || !bytes.Equal(gz, Gzip([]byte(lip))) {
+		t.Errorf("[FAIL] gzip codec should match Gzip (%v)", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if err := Register(identityCodec{}); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if err := Register(identityCodec{}); !errors.Is(err, ErrCodecExists) {
+		t.Errorf("[FAIL] wanted ErrCodecExists, got %v", err)
+	}
+	if err := Register(gzipCodec{}); !errors.Is(err, ErrCodecExists) {
+		t.Errorf("[FAIL] wanted ErrCodecExists, got %v", err)
+	}
+	packed, err := Compress("identity", []byte(lip))
+	if err != nil || string(packed) != lip {
+		t.Fatalf("[FAIL] identity codec failed: %v", err)
+	}
+	found := false
+	for _, name := range Codecs() {
+		if name == "identity" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("[FAIL] registered codec missing from Codecs(): %v", Codecs())
+	}
+
+	if _, err = Compress("yeet", nil); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("[FAIL] wanted ErrUnknownCodec, got %v", err)
+	}
+	if _, err = Decompress("yeet", nil); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("[FAIL] wanted ErrUnknownCodec, got %v", err)
+	}
+}
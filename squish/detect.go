@@ -0,0 +1,289 @@
+package squish
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Format is a compression format or text armor recognized by [Detect].
+type Format uint8
+
+const (
+	// FormatRaw is anything that isn't recognized, e.g. uncompressed data.
+	FormatRaw Format = iota
+	FormatGzip
+	FormatZlib
+	FormatBzip2
+	// FormatBase64 is standard (padded or unpadded) base64 text armor.
+	FormatBase64
+	// FormatBase64URL is URL-safe (padded or unpadded) base64 text armor.
+	FormatBase64URL
+)
+
+var formatToString = map[Format]string{
+	FormatRaw: "raw", FormatGzip: "gzip", FormatZlib: "zlib",
+	FormatBzip2: "bzip2", FormatBase64: "base64", FormatBase64URL: "base64url",
+}
+
+func (f Format) String() string {
+	s, ok := formatToString[f]
+	if !ok {
+		return "unknown"
+	}
+	return s
+}
+
+// Compressed reports whether the format is a compression format, as opposed to raw data or text armor.
+func (f Format) Compressed() bool {
+	return f == FormatGzip || f == FormatZlib || f == FormatBzip2
+}
+
+const (
+	// sniffLen is how much of the input is inspected by Detect and AutoDecompress.
+	sniffLen = 512
+	// minTextLen is the fewest decoded bytes that base64 armor around plain text may hold.
+	minTextLen = 16
+	// maxLayers bounds how many layers of armor/compression AutoDecompress will peel.
+	maxLayers = 4
+)
+
+// ErrTooManyLayers is returned by [AutoDecompress] when the input is nested more than a few layers deep.
+var ErrTooManyLayers = errors.New("squish: too many layers of encoding")
+
+// Detect sniffs the leading bytes of data and reports its [Format].
+//
+// Compression formats are detected by their magic bytes. Base64 detection is heuristic: the leading
+// bytes must be entirely base64 (line breaks allowed), decode cleanly, and decode into a compressed
+// format, another layer of base64, or mostly printable UTF-8 text. Anything else, including hex strings
+// and words that happen to be valid base64, is [FormatRaw].
+func Detect(data []byte) Format {
+	return detect(data, len(data) <= sniffLen)
+}
+
+func detect(data []byte, complete bool) Format {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+	if f := detectMagic(data); f != FormatRaw {
+		return f
+	}
+	return detectArmor(data, complete)
+}
+
+func detectMagic(data []byte) Format {
+	switch {
+	case len(data) >= 3 && data[0] == 0x1f && data[1] == 0x8b && data[2] == 8:
+		return FormatGzip
+	case isBzip2(data):
+		return FormatBzip2
+	case isZlib(data):
+		return FormatZlib
+	default:
+		return FormatRaw
+	}
+}
+
+var (
+	bzip2BlockMagic = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2EOSMagic   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// isBzip2 checks for the stream header followed by either a block or end of stream magic.
+func isBzip2(data []byte) bool {
+	if len(data) < 10 || !bytes.HasPrefix(data, []byte("BZh")) || data[3] < '1' || data[3] > '9' {
+		return false
+	}
+	return bytes.Equal(data[4:10], bzip2BlockMagic) || bytes.Equal(data[4:10], bzip2EOSMagic)
+}
+
+// isZlib checks for a deflate zlib header (RFC 1950) without a preset dictionary.
+// Plenty of ASCII happens to form a valid two byte header (e.g. "HK"), so the sniffed data is also
+// trial inflated, and rejected if it is not a valid deflate stream as far as it goes.
+func isZlib(data []byte) bool {
+	const fdict = 0x20
+	if len(data) < 2 {
+		return false
+	}
+	cmf, flg := data[0], data[1]
+	if cmf&0x0f != 8 || cmf>>4 > 7 || flg&fdict != 0 || (uint16(cmf)<<8|uint16(flg))%31 != 0 {
+		return false
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	// bounded, as a 512 byte deflate stream can still inflate to about half a megabyte.
+	_, err = io.Copy(io.Discard, io.LimitReader(zr, 64*sniffLen))
+	return err == nil || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func armorEncoding(data []byte, complete bool) (enc *base64.Encoding, f Format, ok bool) {
+	var url, std, pad bool
+	n := 0
+scan:
+	for i, c := range data {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == '+' || c == '/':
+			std = true
+		case c == '-' || c == '_':
+			url = true
+		case c == '=':
+			pad = true
+		case c == '\r' || c == '\n':
+			continue
+		default:
+			// tolerate trailing whitespace on complete inputs, e.g. from `base64 < file`
+			if complete && len(bytes.TrimSpace(data[i:])) == 0 {
+				break scan
+			}
+			return nil, FormatRaw, false
+		}
+		if pad && c != '=' {
+			return nil, FormatRaw, false
+		}
+		n++
+	}
+	if (url && std) || n == 0 {
+		return nil, FormatRaw, false
+	}
+	f = FormatBase64
+	if url {
+		f = FormatBase64URL
+	}
+	switch {
+	case pad || !complete || n%4 == 0:
+		enc = base64.StdEncoding
+		if url {
+			enc = base64.URLEncoding
+		}
+	default:
+		enc = base64.RawStdEncoding
+		if url {
+			enc = base64.RawURLEncoding
+		}
+	}
+	return enc, f, true
+}
+
+func detectArmor(data []byte, complete bool) Format {
+	enc, f, ok := armorEncoding(data, complete)
+	if !ok {
+		return FormatRaw
+	}
+	clean := bytes.ReplaceAll(bytes.ReplaceAll(bytes.TrimSpace(data), []byte("\n"), nil), []byte("\r"), nil)
+	if !complete {
+		clean = clean[:len(clean)/4*4]
+	}
+	decoded := make([]byte, enc.DecodedLen(len(clean)))
+	n, err := enc.Decode(decoded, clean)
+	if err != nil || n == 0 {
+		return FormatRaw
+	}
+	// hex IDs, tokens and plain words are valid base64 too, so armor is only
+	// worth unwrapping when there is something recognizable underneath.
+	if detectMagic(decoded[:n]).Compressed() || detectArmor(decoded[:n], complete) != FormatRaw ||
+		isText(decoded[:n], complete) {
+		return f
+	}
+	return FormatRaw
+}
+
+// isText reports whether data is valid UTF-8 made up of mostly printable characters. If data isn't complete,
+// a rune cut off at the end is ignored. Short input is never text, random bytes are too likely to pass.
+func isText(data []byte, complete bool) bool {
+	if len(data) < minTextLen {
+		return false
+	}
+	var runes, other int
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			if !complete && !utf8.FullRune(data[i:]) {
+				break
+			}
+			return false
+		}
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			other++
+		}
+		runes++
+		i += size
+	}
+	return other*20 <= runes
+}
+
+// AutoDecompress sniffs r and returns a reader that yields its decoded contents, peeling off base64
+// armor and gzip, zlib or bzip2 compression as needed (e.g. base64 wrapped gzip).
+// Data that isn't recognized is passed through untouched.
+//
+// If [Limits] are given, they apply to the final output relative to the bytes consumed from r,
+// and reads return [ErrLimitExceeded] as soon as one is crossed.
+func AutoDecompress(r io.Reader, limits ...Limits) (io.Reader, error) {
+	in := &countingReader{r: r}
+	lim := firstLimits(limits)
+	br := bufio.NewReaderSize(in, sniffLen)
+	for layer := 0; ; layer++ {
+		peek, err := br.Peek(sniffLen)
+		complete := errors.Is(err, io.EOF)
+		if err != nil && !complete {
+			return nil, err
+		}
+		f := detect(peek, complete)
+		if f == FormatRaw {
+			return limitReader(br, in, lim), nil
+		}
+		if layer == maxLayers {
+			return nil, ErrTooManyLayers
+		}
+		var next io.Reader
+		err = nil
+		switch f {
+		case FormatGzip:
+			next, err = gzip.NewReader(br)
+		case FormatZlib:
+			next, err = zlib.NewReader(br)
+		case FormatBzip2:
+			next = bzip2.NewReader(br)
+		default:
+			enc, _, _ := armorEncoding(peek, complete)
+			// padding can't be known until the end of a stream, so strip it and decode unpadded.
+			next = base64.NewDecoder(enc.WithPadding(base64.NoPadding), armorReader{br})
+		}
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReaderSize(next, sniffLen)
+	}
+}
+
+// armorReader drops whitespace and padding from base64 text armor.
+type armorReader struct {
+	r io.Reader
+}
+
+func (a armorReader) Read(p []byte) (int, error) {
+	for {
+		n, err := a.r.Read(p)
+		kept := 0
+		for _, c := range p[:n] {
+			switch c {
+			case ' ', '\t', '\r', '\n', '=':
+			default:
+				p[kept] = c
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
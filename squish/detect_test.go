@@ -0,0 +1,141 @@
+package squish
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// bzip2 compressed "hello bzip2 world\n", the stdlib has no bzip2 compressor.
+const helloBz2 = `QlpoOTFBWSZTWaRTSlAAAAPZgAAQQAAQABZk0JAgACKYE2hqEAABw9xY8dyOE4D8XckU4UJCkU0pQA==`
+
+// inputs that are valid base64 but aren't meant as such, and must be passed through untouched.
+const (
+	sha256Empty = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	longWord    = "Supercalifragilisticexpialidocious"
+)
+
+func TestDetect(t *testing.T) {
+	zlibd, err := Compress(CodecZlib, []byte(lip))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	bz2, _ := base64.StdEncoding.DecodeString(helloBz2)
+	gzd := Gzip([]byte(lip))
+	tiny := Gzip([]byte("yeet"))
+
+	cases := []struct {
+		name string
+		data []byte
+		want Format
+	}{
+		{"gzip", gzd, FormatGzip},
+		{"zlib", zlibd, FormatZlib},
+		{"bzip2", bz2, FormatBzip2},
+		{"lorem", []byte(lip), FormatRaw},
+		{"junk", []byte("junk"), FormatRaw},
+		{"empty", nil, FormatRaw},
+		{"zlib-looking text", []byte("HKEY_LOCAL_MACHINE\\SOFTWARE"), FormatRaw},
+		{"bzip2-looking text", []byte("BZh9 is not bzip2 at all"), FormatRaw},
+		{"short word", []byte("deadbeef"), FormatRaw},
+		{"base64 gzip", []byte(lipGzd), FormatBase64},
+		{"base64 with newline", []byte(B64e(gzd) + "\n"), FormatBase64},
+		{"base64 wrapped", []byte(wrap(B64e(gzd), 76)), FormatBase64},
+		{"base64 raw text", []byte(B64e([]byte(lip))), FormatBase64},
+		{"short base64 text", []byte(B64e([]byte("hello"))), FormatRaw},
+		{"sha256 hex", []byte(sha256Empty), FormatRaw},
+		{"hex token", []byte("deadbeefcafebabe"), FormatRaw},
+		{"long word", []byte(longWord), FormatRaw},
+		{"base64url", []byte(base64.URLEncoding.EncodeToString(gzd)), FormatBase64URL},
+		{"base64url unpadded", []byte(base64.RawURLEncoding.EncodeToString(tiny)), FormatBase64URL},
+		{"short base64 gzip", []byte(B64e(tiny)), FormatBase64},
+	}
+	for _, c := range cases {
+		if got := Detect(c.data); got != c.want {
+			t.Errorf("[FAIL] %s: wanted %s, got %s", c.name, c.want.String(), got.String())
+		}
+	}
+	if Format(94).String() != "unknown" {
+		t.Errorf("[FAIL] wanted unknown, got %s", Format(94).String())
+	}
+}
+
+func wrap(s string, width int) string {
+	var sb strings.Builder
+	for len(s) > width {
+		sb.WriteString(s[:width])
+		sb.WriteString("\r\n")
+		s = s[width:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
+
+func TestAutoDecompress(t *testing.T) {
+	zlibd, err := Compress(CodecZlib, []byte(lip))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	bz2, _ := base64.StdEncoding.DecodeString(helloBz2)
+	gzd := Gzip([]byte(lip))
+
+	cases := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"raw", []byte(lip), lip},
+		{"empty", nil, ""},
+		{"gzip", gzd, lip},
+		{"zlib", zlibd, lip},
+		{"bzip2", bz2, "hello bzip2 world\n"},
+		{"base64 gzip", []byte(lipGzd), lip},
+		{"base64 wrapped gzip", []byte(wrap(B64e(gzd), 64) + "\n"), lip},
+		{"base64url zlib", []byte(base64.RawURLEncoding.EncodeToString(zlibd)), lip},
+		{"base64 base64 gzip", []byte(B64e([]byte(B64e(gzd)))), lip},
+		{"gzip base64 text", Gzip([]byte(B64e([]byte(lip)))), lip},
+		{"base64 text", []byte(B64e([]byte(lip))), lip},
+		// the sniffed prefix ends partway through a rune
+		{"base64 utf-8 text", []byte(B64e([]byte(strings.Repeat("日本語 ", 64)))), strings.Repeat("日本語 ", 64)},
+		{"sha256 hex", []byte(sha256Empty), sha256Empty},
+		{"sha256 hex with newline", []byte(sha256Empty + "\n"), sha256Empty + "\n"},
+		{"hex token", []byte("deadbeefcafebabe"), "deadbeefcafebabe"},
+		{"long word", []byte(longWord), longWord},
+	}
+	for _, c := range cases {
+		r, err := AutoDecompress(bytes.NewReader(c.data))
+		if err != nil {
+			t.Fatalf("[FAIL] %s: %s", c.name, err.Error())
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("[FAIL] %s: %s", c.name, err.Error())
+		}
+		if string(got) != c.want {
+			t.Errorf("[FAIL] %s: output mismatch, got %d bytes, wanted %d", c.name, len(got), len(c.want))
+		}
+	}
+
+	corrupt := append([]byte{}, gzd...)
+	for i := 10; i < len(corrupt)-8; i++ {
+		corrupt[i] ^= 0xff
+	}
+	r, err := AutoDecompress(bytes.NewReader(corrupt))
+	if err == nil {
+		_, err = io.ReadAll(r)
+	}
+	if err == nil {
+		t.Error("[FAIL] corrupt gzip should fail to decompress")
+	}
+
+	nested := gzd
+	for i := 0; i <= maxLayers; i++ {
+		nested = Gzip(nested)
+	}
+	if _, err = AutoDecompress(bytes.NewReader(nested)); !errors.Is(err, ErrTooManyLayers) {
+		t.Errorf("[FAIL] wanted ErrTooManyLayers, got %v", err)
+	}
+}
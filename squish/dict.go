@@ -0,0 +1,313 @@
+package squish
+
+import (
+	"bytes"
+	"compress/flate"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"sync"
+)
+
+// MaxDictionarySize is the largest useful preset dictionary, the size of the deflate window.
+// Longer dictionaries are truncated to their last MaxDictionarySize bytes.
+const MaxDictionarySize = 32 << 10
+
+const (
+	dictFrameVersion = 1
+	// magic, version, dictionary ID
+	dictHeaderLen = 3 + 1 + 4
+	// crc32 of the uncompressed data
+	dictTrailerLen = 4
+)
+
+var dictMagic = []byte("SQD")
+
+var (
+	// ErrUnknownDictionary is returned when decompressing a frame whose dictionary has not been registered.
+	ErrUnknownDictionary = errors.New("squish: unknown dictionary")
+	// ErrDictionaryExists is returned when registering a different dictionary under an ID that is already taken.
+	ErrDictionaryExists = errors.New("squish: dictionary already registered")
+	// ErrMalformedFrame is returned when decompressing data that is not a valid dictionary frame.
+	ErrMalformedFrame = errors.New("squish: malformed dictionary frame")
+)
+
+// Dictionary is a preset deflate dictionary. Compressing small payloads against a dictionary of
+// content that is typical for them (see [TrainDictionary]) avoids the cold start of an empty window.
+//
+// Payloads are compressed into a small self-describing frame that records the dictionary ID,
+// so that [DecompressDict] can pick the right dictionary from the registry.
+// Frames are laid out as "SQD", a version byte, the big endian dictionary ID,
+// a raw deflate stream, and the big endian crc32 (IEEE) of the uncompressed data.
+type Dictionary struct {
+	id      uint32
+	data    []byte
+	writers *sync.Pool
+	readers *sync.Pool
+}
+
+// NewDictionary returns a [Dictionary] over a copy of data.
+// Its ID is the adler32 checksum of the dictionary, as with zlib's DICTID.
+func NewDictionary(data []byte) *Dictionary {
+	if len(data) > MaxDictionarySize {
+		data = data[len(data)-MaxDictionarySize:]
+	}
+	d := &Dictionary{data: append([]byte(nil), data...)}
+	d.id = adler32.Checksum(d.data)
+	d.writers = &sync.Pool{
+		New: func() interface{} {
+			fw, _ := flate.NewWriterDict(nil, flate.DefaultCompression, d.data)
+			return fw
+		},
+	}
+	d.readers = &sync.Pool{
+		New: func() interface{} {
+			return flate.NewReaderDict(nil, d.data)
+		},
+	}
+	return d
+}
+
+// ID returns the identifier recorded in frames compressed with this dictionary.
+func (d *Dictionary) ID() uint32 {
+	return d.id
+}
+
+// Bytes returns the dictionary contents. It must not be modified.
+func (d *Dictionary) Bytes() []byte {
+	return d.data
+}
+
+// Compress compresses data against the dictionary into a self-describing frame.
+func (d *Dictionary) Compress(data []byte) []byte {
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	buf.Write(dictMagic)
+	buf.WriteByte(dictFrameVersion)
+	_ = binary.Write(buf, binary.BigEndian, d.id)
+
+	fw := d.writers.Get().(*flate.Writer)
+	fw.Reset(buf)
+	_, _ = fw.Write(data)
+	_ = fw.Close()
+	fw.Reset(nil)
+	d.writers.Put(fw)
+
+	_ = binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(data))
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res
+}
+
+// Decompress decompresses a frame that was compressed with this dictionary.
+// If [Limits] are given, decompression stops with [ErrLimitExceeded] as soon as one is crossed.
+func (d *Dictionary) Decompress(frame []byte, limits ...Limits) ([]byte, error) {
+	id, err := FrameDictionaryID(frame)
+	if err != nil {
+		return nil, err
+	}
+	if id != d.id {
+		return nil, fmt.Errorf("%w: frame uses dictionary %08x, not %08x", ErrMalformedFrame, id, d.id)
+	}
+
+	body := frame[dictHeaderLen : len(frame)-dictTrailerLen]
+	in := &countingReader{r: bytes.NewReader(body)}
+	fr := d.readers.Get().(io.ReadCloser)
+	defer d.readers.Put(fr)
+	if err = fr.(flate.Resetter).Reset(in, d.data); err != nil {
+		return nil, err
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	if _, err = buf.ReadFrom(limitReader(fr, in, firstLimits(limits))); err != nil {
+		return nil, err
+	}
+	if binary.BigEndian.Uint32(frame[len(frame)-dictTrailerLen:]) != crc32.ChecksumIEEE(buf.Bytes()) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrMalformedFrame)
+	}
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res, nil
+}
+
+// FrameDictionaryID returns the dictionary ID recorded in a frame produced by [Dictionary.Compress].
+func FrameDictionaryID(frame []byte) (uint32, error) {
+	if len(frame) < dictHeaderLen+dictTrailerLen || !bytes.HasPrefix(frame, dictMagic) {
+		return 0, ErrMalformedFrame
+	}
+	if v := frame[len(dictMagic)]; v != dictFrameVersion {
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrMalformedFrame, v)
+	}
+	return binary.BigEndian.Uint32(frame[len(dictMagic)+1:]), nil
+}
+
+var (
+	dictsMu = &sync.RWMutex{}
+	dicts   = map[uint32]*Dictionary{}
+)
+
+// RegisterDictionary adds a [Dictionary] to the registry used by [DecompressDict].
+// Registering the same dictionary twice is a no-op; registering a different dictionary with
+// a colliding ID returns [ErrDictionaryExists].
+func RegisterDictionary(d *Dictionary) error {
+	dictsMu.Lock()
+	defer dictsMu.Unlock()
+	if existing, ok := dicts[d.id]; ok {
+		if bytes.Equal(existing.data, d.data) {
+			return nil
+		}
+		return fmt.Errorf("%w: %08x", ErrDictionaryExists, d.id)
+	}
+	dicts[d.id] = d
+	return nil
+}
+
+// LookupDictionary returns the registered [Dictionary] with the given ID.
+func LookupDictionary(id uint32) (*Dictionary, error) {
+	dictsMu.RLock()
+	d, ok := dicts[id]
+	dictsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %08x", ErrUnknownDictionary, id)
+	}
+	return d, nil
+}
+
+// DecompressDict decompresses a frame produced by [Dictionary.Compress], looking up the
+// dictionary it was compressed with in the registry (see [RegisterDictionary]).
+// If [Limits] are given, decompression stops with [ErrLimitExceeded] as soon as one is crossed.
+func DecompressDict(frame []byte, limits ...Limits) ([]byte, error) {
+	id, err := FrameDictionaryID(frame)
+	if err != nil {
+		return nil, err
+	}
+	d, err := LookupDictionary(id)
+	if err != nil {
+		return nil, err
+	}
+	return d.Decompress(frame, limits...)
+}
+
+const (
+	// trainKmer is the length of the substrings counted by TrainDictionary.
+	trainKmer = 8
+	// trainSegment is the length of the candidate segments TrainDictionary picks from.
+	trainSegment = 64
+)
+
+// TrainDictionary builds a preset dictionary of at most size bytes (capped at [MaxDictionarySize])
+// from representative samples, for use with [NewDictionary].
+//
+// It is a simplified take on zstd's COVER algorithm: every 8 byte substring is scored by the number
+// of samples it appears in, and overlapping 64 byte segments of the samples are greedily picked by the
+// total score of the substrings they contain that are not already in the dictionary. The best segments
+// are placed at the end, where deflate can reference them most cheaply.
+// Substrings that only appear in one sample do not count, so a few dozen samples are needed at the least.
+func TrainDictionary(samples [][]byte, size int) []byte {
+	size = min(size, MaxDictionarySize)
+	if size <= 0 {
+		return nil
+	}
+
+	freq := make(map[uint64]int)
+	seen := make(map[uint64]struct{})
+	for _, s := range samples {
+		clear(seen)
+		for i := 0; i+trainKmer <= len(s); i++ {
+			k := binary.LittleEndian.Uint64(s[i:])
+			if _, ok := seen[k]; !ok {
+				seen[k] = struct{}{}
+				freq[k]++
+			}
+		}
+	}
+
+	t := &trainer{freq: freq, seen: seen}
+	for si, s := range samples {
+		for start := 0; start+trainKmer <= len(s); start += trainSegment / 2 {
+			seg := segment{sample: si, start: start, end: min(start+trainSegment, len(s))}
+			seg.score = t.score(samples[si][seg.start:seg.end])
+			if seg.score > 0 {
+				t.segs = append(t.segs, seg)
+			}
+		}
+	}
+	heap.Init(t)
+
+	var picked [][]byte
+	total := 0
+	for t.Len() > 0 && total < size {
+		seg := heap.Pop(t).(segment)
+		data := samples[seg.sample][seg.start:seg.end]
+		// scores only ever go down as the dictionary grows, so re-score lazily.
+		if seg.score = t.score(data); seg.score == 0 {
+			continue
+		}
+		if t.Len() > 0 && seg.score < t.segs[0].score {
+			heap.Push(t, seg)
+			continue
+		}
+		if len(data) > size-total {
+			data = data[:size-total]
+		}
+		picked = append(picked, data)
+		total += len(data)
+		for i := 0; i+trainKmer <= len(data); i++ {
+			delete(freq, binary.LittleEndian.Uint64(data[i:]))
+		}
+	}
+
+	dict := make([]byte, 0, total)
+	for i := len(picked) - 1; i >= 0; i-- {
+		dict = append(dict, picked[i]...)
+	}
+	return dict
+}
+
+type segment struct {
+	sample, start, end int
+	score              int
+}
+
+// trainer is a max-heap of candidate segments by score.
+type trainer struct {
+	segs []segment
+	freq map[uint64]int
+	seen map[uint64]struct{}
+}
+
+// score sums the frequencies of the distinct substrings of data that are shared between samples
+// and not yet covered by the dictionary.
+func (t *trainer) score(data []byte) int {
+	clear(t.seen)
+	score := 0
+	for i := 0; i+trainKmer <= len(data); i++ {
+		k := binary.LittleEndian.Uint64(data[i:])
+		if _, ok := t.seen[k]; ok {
+			continue
+		}
+		t.seen[k] = struct{}{}
+		if f := t.freq[k]; f > 1 {
+			score += f
+		}
+	}
+	return score
+}
+
+func (t *trainer) Len() int           { return len(t.segs) }
+func (t *trainer) Less(i, j int) bool { return t.segs[i].score > t.segs[j].score }
+func (t *trainer) Swap(i, j int)      { t.segs[i], t.segs[j] = t.segs[j], t.segs[i] }
+func (t *trainer) Push(x interface{}) { t.segs = append(t.segs, x.(segment)) }
+
+func (t *trainer) Pop() interface{} {
+	seg := t.segs[len(t.segs)-1]
+	t.segs = t.segs[:len(t.segs)-1]
+	return seg
+}
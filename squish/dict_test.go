@@ -0,0 +1,99 @@
+package squish
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func jsonRecord(i int) []byte {
+	return []byte(fmt.Sprintf(
+		`{"id":%d,"username":"user%04d","status":"active","roles":["admin","viewer"],`+
+			`"created_at":"2023-01-%02dT12:00:00Z","banner":"SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.%d"}`,
+		i*7919, i, i%28+1, i%10))
+}
+
+func TestDictionary(t *testing.T) {
+	samples := make([][]byte, 0, 200)
+	for i := 0; i < 200; i++ {
+		samples = append(samples, jsonRecord(i))
+	}
+	raw := TrainDictionary(samples, 4096)
+	if len(raw) == 0 || len(raw) > 4096 {
+		t.Fatalf("[FAIL] wanted a dictionary of up to 4096 bytes, got %d", len(raw))
+	}
+	d := NewDictionary(raw)
+	if err := RegisterDictionary(d); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if err := RegisterDictionary(NewDictionary(raw)); err != nil {
+		t.Errorf("[FAIL] registering the same dictionary twice should be a no-op: %v", err)
+	}
+
+	payload := jsonRecord(1337)
+	framed := d.Compress(payload)
+	plain := Gzip(payload)
+	t.Logf("%d bytes: gzip %d, dictionary frame %d", len(payload), len(plain), len(framed))
+	if len(framed) >= len(plain)/2 {
+		t.Errorf("[FAIL] dictionary compression should do a lot better than gzip: %d >= %d/2", len(framed), len(plain))
+	}
+
+	for i := 0; i < 3; i++ {
+		out, err := DecompressDict(framed)
+		if err != nil || string(out) != string(payload) {
+			t.Fatalf("[FAIL] round trip failed: %v", err)
+		}
+	}
+	if id, err := FrameDictionaryID(framed); err != nil || id != d.ID() {
+		t.Errorf("[FAIL] wanted dictionary ID %08x, got %08x (%v)", d.ID(), id, err)
+	}
+	if out, err := NewDictionary(nil).Decompress(NewDictionary(nil).Compress(payload)); err != nil || string(out) != string(payload) {
+		t.Errorf("[FAIL] empty dictionary round trip failed: %v", err)
+	}
+}
+
+func TestDictionaryErrors(t *testing.T) {
+	d := NewDictionary([]byte("some dictionary that is never registered"))
+	framed := d.Compress([]byte(lip))
+	if _, err := DecompressDict(framed); !errors.Is(err, ErrUnknownDictionary) {
+		t.Errorf("[FAIL] wanted ErrUnknownDictionary, got %v", err)
+	}
+	if _, err := NewDictionary([]byte("another")).Decompress(framed); !errors.Is(err, ErrMalformedFrame) {
+		t.Errorf("[FAIL] wrong dictionary: wanted ErrMalformedFrame, got %v", err)
+	}
+
+	corrupt := append([]byte{}, framed...)
+	corrupt[len(corrupt)-1] ^= 0xff
+	if _, err := d.Decompress(corrupt); !errors.Is(err, ErrMalformedFrame) {
+		t.Errorf("[FAIL] bad checksum: wanted ErrMalformedFrame, got %v", err)
+	}
+	for _, bad := range [][]byte{nil, []byte("junk"), Gzip([]byte(lip)), append([]byte("SQD\x02"), framed[4:]...)} {
+		if _, err := DecompressDict(bad); !errors.Is(err, ErrMalformedFrame) {
+			t.Errorf("[FAIL] %q: wanted ErrMalformedFrame, got %v", bad, err)
+		}
+	}
+
+	bomb := d.Compress(make([]byte, bombSize))
+	if _, err := d.Decompress(bomb, Limits{MaxOutput: 1 << 20}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] wanted ErrLimitExceeded, got %v", err)
+	}
+
+	if TrainDictionary([][]byte{[]byte(lip)}, 0) != nil {
+		t.Error("[FAIL] zero size dictionary should be nil")
+	}
+	if got := TrainDictionary([][]byte{[]byte(lip), []byte(lip)}, 1<<20); len(got) > MaxDictionarySize {
+		t.Errorf("[FAIL] dictionary should be capped at %d bytes, got %d", MaxDictionarySize, len(got))
+	}
+}
+
+func BenchmarkTrainDictionary(b *testing.B) {
+	samples := make([][]byte, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		samples = append(samples, jsonRecord(i))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		TrainDictionary(samples, MaxDictionarySize)
+	}
+}
@@ -0,0 +1,199 @@
+package squish
+
+import (
+	"bytes"
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encoding is a binary-to-text encoding supported by [Encode], [Decode], [NewEncoder] and [NewDecoder].
+type Encoding uint8
+
+const (
+	// EncodingBase64 is standard padded base64, as used by [B64e].
+	EncodingBase64 Encoding = iota
+	// EncodingBase64URL is URL and filename safe padded base64.
+	EncodingBase64URL
+	// EncodingBase64Raw is standard unpadded base64.
+	EncodingBase64Raw
+	// EncodingBase64RawURL is URL and filename safe unpadded base64, the usual choice for tokens.
+	EncodingBase64RawURL
+	// EncodingBase32 is standard padded base32.
+	EncodingBase32
+	// EncodingHex is lowercase hexadecimal.
+	EncodingHex
+	// EncodingASCII85 is ascii85 (btoa) without the <~ ~> delimiters.
+	EncodingASCII85
+)
+
+// ErrUnknownEncoding is returned when using an [Encoding] that does not exist.
+var ErrUnknownEncoding = errors.New("squish: unknown encoding")
+
+var encodingToString = map[Encoding]string{
+	EncodingBase64: "base64", EncodingBase64URL: "base64url", EncodingBase64Raw: "base64raw",
+	EncodingBase64RawURL: "base64rawurl", EncodingBase32: "base32", EncodingHex: "hex", EncodingASCII85: "ascii85",
+}
+
+var encodingToBase64 = map[Encoding]*base64.Encoding{
+	EncodingBase64: base64.StdEncoding, EncodingBase64URL: base64.URLEncoding,
+	EncodingBase64Raw: base64.RawStdEncoding, EncodingBase64RawURL: base64.RawURLEncoding,
+}
+
+func (e Encoding) String() string {
+	s, ok := encodingToString[e]
+	if !ok {
+		return "unknown"
+	}
+	return s
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// NewEncoder returns a streaming encoder that writes the e encoded form of everything written to it into w.
+// Close must be called to flush any partially encoded block; it does not close w.
+func NewEncoder(e Encoding, w io.Writer) (io.WriteCloser, error) {
+	if b64, ok := encodingToBase64[e]; ok {
+		return base64.NewEncoder(b64, w), nil
+	}
+	switch e {
+	case EncodingBase32:
+		return base32.NewEncoder(base32.StdEncoding, w), nil
+	case EncodingHex:
+		return nopCloser{hex.NewEncoder(w)}, nil
+	case EncodingASCII85:
+		return ascii85.NewEncoder(w), nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownEncoding, e)
+	}
+}
+
+// NewDecoder returns a streaming decoder that reads e encoded data from r.
+// Corrupt input results in an error from Read.
+func NewDecoder(e Encoding, r io.Reader) (io.Reader, error) {
+	if b64, ok := encodingToBase64[e]; ok {
+		return base64.NewDecoder(b64, r), nil
+	}
+	switch e {
+	case EncodingBase32:
+		return base32.NewDecoder(base32.StdEncoding, r), nil
+	case EncodingHex:
+		return hex.NewDecoder(r), nil
+	case EncodingASCII85:
+		return ascii85.NewDecoder(r), nil
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnknownEncoding, e)
+	}
+}
+
+// Encode encodes the given slice of bytes with e.
+func Encode(e Encoding, in []byte) (string, error) {
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	enc, err := NewEncoder(e, buf)
+	if err != nil {
+		return "", err
+	}
+	_, _ = enc.Write(in)
+	_ = enc.Close()
+	return buf.String(), nil
+}
+
+// Decode decodes the given string with e. Unlike [B64d], corrupt input is reported as an error;
+// empty input decodes to an empty result without error.
+func Decode(e Encoding, str string) ([]byte, error) {
+	if len(str) == 0 {
+		return nil, nil
+	}
+	dec, err := NewDecoder(e, strings.NewReader(str))
+	if err != nil {
+		return nil, err
+	}
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	if _, err = buf.ReadFrom(dec); err != nil {
+		return nil, err
+	}
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res, nil
+}
+
+func mustEncode(e Encoding, in []byte) string {
+	s, _ := Encode(e, in)
+	return s
+}
+
+// B64dErr decodes the given standard base64 string, returning any decoding error unlike [B64d].
+func B64dErr(str string) ([]byte, error) {
+	return Decode(EncodingBase64, str)
+}
+
+// B64URLe encodes the given slice of bytes into URL-safe padded base64.
+func B64URLe(in []byte) string {
+	return mustEncode(EncodingBase64URL, in)
+}
+
+// B64URLd decodes the given URL-safe padded base64 string.
+func B64URLd(str string) ([]byte, error) {
+	return Decode(EncodingBase64URL, str)
+}
+
+// B64Rawe encodes the given slice of bytes into standard unpadded base64.
+func B64Rawe(in []byte) string {
+	return mustEncode(EncodingBase64Raw, in)
+}
+
+// B64Rawd decodes the given standard unpadded base64 string.
+func B64Rawd(str string) ([]byte, error) {
+	return Decode(EncodingBase64Raw, str)
+}
+
+// B64RawURLe encodes the given slice of bytes into URL-safe unpadded base64, e.g. for tokens.
+func B64RawURLe(in []byte) string {
+	return mustEncode(EncodingBase64RawURL, in)
+}
+
+// B64RawURLd decodes the given URL-safe unpadded base64 string.
+func B64RawURLd(str string) ([]byte, error) {
+	return Decode(EncodingBase64RawURL, str)
+}
+
+// B32e encodes the given slice of bytes into standard base32.
+func B32e(in []byte) string {
+	return mustEncode(EncodingBase32, in)
+}
+
+// B32d decodes the given standard base32 string.
+func B32d(str string) ([]byte, error) {
+	return Decode(EncodingBase32, str)
+}
+
+// Hexe encodes the given slice of bytes into lowercase hex.
+func Hexe(in []byte) string {
+	return mustEncode(EncodingHex, in)
+}
+
+// Hexd decodes the given hex string.
+func Hexd(str string) ([]byte, error) {
+	return Decode(EncodingHex, str)
+}
+
+// A85e encodes the given slice of bytes into ascii85.
+func A85e(in []byte) string {
+	return mustEncode(EncodingASCII85, in)
+}
+
+// A85d decodes the given ascii85 string.
+func A85d(str string) ([]byte, error) {
+	return Decode(EncodingASCII85, str)
+}
@@ -0,0 +1,113 @@
+package squish
+
+import (
+	"bytes"
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func stdlibEncode(e Encoding, in []byte) string {
+	switch e {
+	case EncodingBase32:
+		return base32.StdEncoding.EncodeToString(in)
+	case EncodingHex:
+		return hex.EncodeToString(in)
+	case EncodingASCII85:
+		out := make([]byte, ascii85.MaxEncodedLen(len(in)))
+		return string(out[:ascii85.Encode(out, in)])
+	default:
+		return encodingToBase64[e].EncodeToString(in)
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	helpers := map[Encoding]struct {
+		enc func([]byte) string
+		dec func(string) ([]byte, error)
+	}{
+		EncodingBase64:       {B64e, B64dErr},
+		EncodingBase64URL:    {B64URLe, B64URLd},
+		EncodingBase64Raw:    {B64Rawe, B64Rawd},
+		EncodingBase64RawURL: {B64RawURLe, B64RawURLd},
+		EncodingBase32:       {B32e, B32d},
+		EncodingHex:          {Hexe, Hexd},
+		EncodingASCII85:      {A85e, A85d},
+	}
+	inputs := [][]byte{[]byte("y"), []byte("ye"), []byte("yee"), []byte("yeet"), []byte(lip), Gzip([]byte(lip))}
+
+	for e, h := range helpers {
+		for _, in := range inputs {
+			want := stdlibEncode(e, in)
+			if got := h.enc(in); got != want {
+				t.Errorf("[FAIL] %s: wanted %q, got %q", e.String(), want, got)
+			}
+			out, err := h.dec(want)
+			if err != nil || !bytes.Equal(out, in) {
+				t.Errorf("[FAIL] %s: round trip failed: %v", e.String(), err)
+			}
+		}
+		if out, err := h.dec(""); err != nil || len(out) != 0 {
+			t.Errorf("[FAIL] %s: empty input should decode to nothing, got %v (%v)", e.String(), out, err)
+		}
+	}
+
+	corrupt := map[Encoding]string{
+		EncodingBase64:       "yeet!!==",
+		EncodingBase64URL:    "yeet+/==",
+		EncodingBase64Raw:    "yee=t",
+		EncodingBase64RawURL: "y",
+		EncodingBase32:       "yeet",
+		EncodingHex:          "abc",
+		EncodingASCII85:      "yeet{",
+	}
+	for e, bad := range corrupt {
+		if _, err := Decode(e, bad); err == nil {
+			t.Errorf("[FAIL] %s: corrupt input %q should fail to decode", e.String(), bad)
+		}
+	}
+
+	if _, err := Encode(Encoding(94), nil); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("[FAIL] wanted ErrUnknownEncoding, got %v", err)
+	}
+	if _, err := NewDecoder(Encoding(94), nil); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("[FAIL] wanted ErrUnknownEncoding, got %v", err)
+	}
+	if Encoding(94).String() != "unknown" {
+		t.Errorf("[FAIL] wanted unknown, got %s", Encoding(94).String())
+	}
+}
+
+func TestEncodingStreams(t *testing.T) {
+	for e := EncodingBase64; e <= EncodingASCII85; e++ {
+		buf := new(bytes.Buffer)
+		w, err := NewEncoder(e, buf)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		for _, word := range strings.SplitAfter(lip, " ") {
+			if _, err = w.Write([]byte(word)); err != nil {
+				t.Fatalf("[FAIL] %s: %s", e.String(), err.Error())
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatalf("[FAIL] %s: %s", e.String(), err.Error())
+		}
+		if buf.String() != stdlibEncode(e, []byte(lip)) {
+			t.Errorf("[FAIL] %s: streaming encoder output differs from the one-shot encoding", e.String())
+		}
+
+		r, err := NewDecoder(e, buf)
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		out, err := io.ReadAll(r)
+		if err != nil || string(out) != lip {
+			t.Errorf("[FAIL] %s: streaming round trip failed: %v", e.String(), err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package squish
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLimitExceeded is returned (wrapped in a [*LimitError]) when decompression crosses one of its [Limits].
+var ErrLimitExceeded = errors.New("squish: decompression limit exceeded")
+
+// ratioSlack is how much output is allowed before [Limits.MaxRatio] is enforced, so that small but
+// highly repetitive inputs (which legitimately compress far better than large ones) are not rejected.
+const ratioSlack = 64 * 1024
+
+// Limits bounds how much a decompressor may inflate its input, as protection against decompression bombs.
+// The zero value imposes no limits.
+type Limits struct {
+	// MaxOutput is the maximum number of decompressed bytes. Zero means unlimited.
+	MaxOutput int64
+	// MaxRatio is the maximum ratio of decompressed bytes to compressed bytes consumed. Zero means unlimited.
+	// It is only enforced once more than 64KiB has been decompressed.
+	MaxRatio float64
+}
+
+func (l Limits) enabled() bool {
+	return l.MaxOutput > 0 || l.MaxRatio > 0
+}
+
+// firstLimits returns the first of the optional limits passed to a decompress function, if any.
+func firstLimits(lims []Limits) Limits {
+	if len(lims) == 0 {
+		return Limits{}
+	}
+	return lims[0]
+}
+
+// LimitError describes which of the [Limits] was crossed. It matches [ErrLimitExceeded] with [errors.Is].
+type LimitError struct {
+	Limits Limits
+	// Input is the number of compressed bytes consumed when the limit was crossed.
+	Input int64
+	// Output is the number of decompressed bytes produced when the limit was crossed.
+	Output int64
+}
+
+func (e *LimitError) Error() string {
+	if e.Limits.MaxOutput > 0 && e.Output > e.Limits.MaxOutput {
+		return fmt.Sprintf("%s: output exceeds %d bytes", ErrLimitExceeded.Error(), e.Limits.MaxOutput)
+	}
+	return fmt.Sprintf("%s: ratio exceeds %.1f (%d bytes from %d)",
+		ErrLimitExceeded.Error(), e.Limits.MaxRatio, e.Output, e.Input)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// countingReader counts the compressed bytes a decompressor consumes.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// limitedReader enforces [Limits] on the output of a decompressor reading from in.
+// Reads are clamped so that no more than MaxOutput bytes are ever returned.
+type limitedReader struct {
+	r   io.Reader
+	in  *countingReader
+	out int64
+	lim Limits
+	err error
+}
+
+// limitReader wraps the decompressor r, which reads its compressed input from in, so that it enforces lim.
+func limitReader(r io.Reader, in *countingReader, lim Limits) io.Reader {
+	if !lim.enabled() {
+		return r
+	}
+	return &limitedReader{r: r, in: in, lim: lim}
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	// read one byte past the limit so that output of exactly MaxOutput bytes is allowed.
+	if l.lim.MaxOutput > 0 {
+		if room := l.lim.MaxOutput - l.out + 1; int64(len(p)) > room {
+			p = p[:room]
+		}
+	}
+	n, err := l.r.Read(p)
+	l.out += int64(n)
+	over := l.lim.MaxOutput > 0 && l.out > l.lim.MaxOutput
+	if over {
+		n -= int(l.out - l.lim.MaxOutput)
+	}
+	if !over && !l.ratioExceeded() {
+		return n, err
+	}
+	l.err = &LimitError{Limits: l.lim, Input: l.in.n, Output: l.out}
+	return n, l.err
+}
+
+func (l *limitedReader) ratioExceeded() bool {
+	return l.lim.MaxRatio > 0 && l.out > ratioSlack && float64(l.out) > l.lim.MaxRatio*float64(l.in.n)
+}
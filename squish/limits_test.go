@@ -0,0 +1,91 @@
+package squish
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+const bombSize = 8 << 20
+
+func TestLimits(t *testing.T) {
+	bomb := Gzip(make([]byte, bombSize))
+	t.Logf("%d bytes inflate to %d (ratio %.1f)", len(bomb), bombSize, float64(bombSize)/float64(len(bomb)))
+
+	if out, err := Gunzip(bomb, Limits{MaxOutput: bombSize}); err != nil || len(out) != bombSize {
+		t.Errorf("[FAIL] output of exactly MaxOutput should be allowed, got %d bytes: %v", len(out), err)
+	}
+	if _, err := Gunzip(bomb, Limits{MaxRatio: 2000}); err != nil {
+		t.Errorf("[FAIL] ratio under the limit should be allowed: %v", err)
+	}
+	if out, err := Gunzip(Gzip([]byte(lip)), Limits{MaxRatio: 1}); err != nil || string(out) != lip {
+		t.Errorf("[FAIL] small outputs should not be subject to MaxRatio: %v", err)
+	}
+
+	for _, lim := range []Limits{{MaxOutput: 1 << 20}, {MaxRatio: 100}, {MaxOutput: bombSize - 1, MaxRatio: 1000}} {
+		_, err := Gunzip(bomb, lim)
+		var le *LimitError
+		if !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &le) {
+			t.Fatalf("[FAIL] %+v: wanted ErrLimitExceeded, got %v", lim, err)
+		}
+		if le.Limits != lim {
+			t.Errorf("[FAIL] LimitError should carry the limits that were crossed, got %+v", le.Limits)
+		}
+		if lim.MaxOutput > 0 && le.Output > lim.MaxOutput+1 {
+			t.Errorf("[FAIL] %+v: limit should trip as soon as it is crossed, got %d bytes", lim, le.Output)
+		}
+		if lim.MaxOutput == 0 && le.Output > 1<<20 {
+			t.Errorf("[FAIL] %+v: limit should trip as soon as it is crossed, got %d bytes", lim, le.Output)
+		}
+		t.Logf("[PASS] %s", err.Error())
+	}
+
+	packed, err := Compress(CodecZlib, make([]byte, bombSize))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if _, err = Decompress(CodecZlib, packed, Limits{MaxOutput: 1 << 20}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] Decompress: wanted ErrLimitExceeded, got %v", err)
+	}
+	if _, err = UnpackStr(B64e(bomb), Limits{MaxRatio: 10}); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] UnpackStr: wanted ErrLimitExceeded, got %v", err)
+	}
+}
+
+func TestLimitsStreaming(t *testing.T) {
+	bomb := Gzip(make([]byte, bombSize))
+	const max = 1<<20 + 7
+
+	r, err := NewReader(bytes.NewReader(bomb), Limits{MaxOutput: max})
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	n, err := io.Copy(io.Discard, r)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] NewReader: wanted ErrLimitExceeded, got %v", err)
+	}
+	if n != max {
+		t.Errorf("[FAIL] NewReader: wanted exactly %d bytes before the error, got %d", max, n)
+	}
+	if _, err = r.Read(make([]byte, 1)); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] NewReader: error should be sticky, got %v", err)
+	}
+	_ = r.Close()
+
+	ar, err := AutoDecompress(bytes.NewReader([]byte(B64e(bomb))), Limits{MaxOutput: max})
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if n, err = io.Copy(io.Discard, ar); !errors.Is(err, ErrLimitExceeded) || n != max {
+		t.Errorf("[FAIL] AutoDecompress: wanted ErrLimitExceeded after %d bytes, got %v after %d", max, err, n)
+	}
+
+	ar, err = AutoDecompress(bytes.NewReader(bomb), Limits{MaxRatio: 50})
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if _, err = io.Copy(io.Discard, ar); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("[FAIL] AutoDecompress: wanted ErrLimitExceeded, got %v", err)
+	}
+}
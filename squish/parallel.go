@@ -0,0 +1,177 @@
+package squish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ParallelBlockSize is the amount of uncompressed data per gzip member written by [ParallelWriter].
+const ParallelBlockSize = 1 << 20
+
+var blockPool = &sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, ParallelBlockSize)
+		return &b
+	},
+}
+
+// parallelJob is a block being compressed by a worker goroutine.
+type parallelJob struct {
+	block *[]byte
+	out   *bytes.Buffer
+	err   error
+	done  chan struct{}
+}
+
+// ParallelWriter is a streaming gzip compressor that compresses blocks of [ParallelBlockSize] concurrently.
+// The output is a multi-member gzip stream with one member per block, which any gzip reader
+// (including stock gunzip and [Gunzip]) decompresses as a whole.
+//
+// Close must be called to compress any buffered data and wait for the workers; it does not close
+// the underlying [io.Writer]. A ParallelWriter is not safe for concurrent use.
+type ParallelWriter struct {
+	w       io.Writer
+	pool    *sync.Pool
+	workers int
+	block   *[]byte
+	// pending holds in-flight jobs in the order their output must be written.
+	pending []*parallelJob
+	blocks  int
+	err     error
+}
+
+// NewParallelWriter returns a [ParallelWriter] that compresses to w at the given level using up to
+// workers goroutines. Workers <= 0 means [runtime.GOMAXPROCS].
+func NewParallelWriter(w io.Writer, level, workers int) (*ParallelWriter, error) {
+	pool, ok := gzipPools[level]
+	if !ok {
+		return nil, fmt.Errorf("gzip: invalid compression level: %d", level)
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &ParallelWriter{w: w, pool: pool, workers: workers, pending: make([]*parallelJob, 0, workers)}, nil
+}
+
+// Write buffers p, handing every full block off to a worker. It blocks while all workers are busy.
+func (pw *ParallelWriter) Write(p []byte) (int, error) {
+	if pw.err != nil {
+		return 0, pw.err
+	}
+	n := 0
+	for len(p) > 0 {
+		if pw.block == nil {
+			pw.block = blockPool.Get().(*[]byte)
+		}
+		b := *pw.block
+		take := min(len(p), cap(b)-len(b))
+		*pw.block = append(b, p[:take]...)
+		p = p[take:]
+		n += take
+		if len(*pw.block) == cap(*pw.block) {
+			if pw.err = pw.dispatch(); pw.err != nil {
+				return n, pw.err
+			}
+		}
+	}
+	return n, nil
+}
+
+// dispatch hands the current block to a worker, first writing out the oldest job if all workers are busy.
+func (pw *ParallelWriter) dispatch() error {
+	if len(pw.pending) == pw.workers {
+		if err := pw.writeOldest(); err != nil {
+			return err
+		}
+	}
+	job := &parallelJob{block: pw.block, out: bufPool.Get().(*bytes.Buffer), done: make(chan struct{})}
+	pw.block = nil
+	pw.blocks++
+	pw.pending = append(pw.pending, job)
+	go pw.compress(job)
+	return nil
+}
+
+func (pw *ParallelWriter) compress(job *parallelJob) {
+	defer close(job.done)
+	job.out.Reset()
+	gz := pw.pool.Get().(*gzip.Writer)
+	gz.Reset(job.out)
+	_, job.err = gz.Write(*job.block)
+	if err := gz.Close(); job.err == nil {
+		job.err = err
+	}
+	gz.Reset(nil)
+	pw.pool.Put(gz)
+	*job.block = (*job.block)[:0]
+	blockPool.Put(job.block)
+}
+
+// writeOldest waits for the oldest pending job and writes its output.
+func (pw *ParallelWriter) writeOldest() error {
+	job := pw.pending[0]
+	pw.pending = pw.pending[1:]
+	<-job.done
+	err := job.err
+	if err == nil {
+		_, err = pw.w.Write(job.out.Bytes())
+	}
+	bufPool.Put(job.out)
+	return err
+}
+
+// Flush compresses any buffered data as a (short) member of its own, and writes out every
+// pending block to the underlying writer.
+func (pw *ParallelWriter) Flush() error {
+	if pw.err != nil {
+		return pw.err
+	}
+	if pw.block != nil && len(*pw.block) > 0 {
+		if pw.err = pw.dispatch(); pw.err != nil {
+			return pw.err
+		}
+	}
+	for len(pw.pending) > 0 {
+		if err := pw.writeOldest(); err != nil && pw.err == nil {
+			pw.err = err
+		}
+	}
+	return pw.err
+}
+
+// Close flushes the writer and waits for all workers to finish.
+// Calling Close more than once returns [ErrClosed].
+func (pw *ParallelWriter) Close() error {
+	// an empty file isn't valid gzip, so always write at least one (empty) member.
+	if pw.blocks == 0 && pw.err == nil {
+		if pw.block == nil {
+			pw.block = blockPool.Get().(*[]byte)
+		}
+		pw.err = pw.dispatch()
+	}
+	err := pw.Flush()
+	if pw.block != nil {
+		blockPool.Put(pw.block)
+		pw.block = nil
+	}
+	pw.err = ErrClosed
+	return err
+}
+
+// GzipParallel compresses data using up to workers goroutines, see [ParallelWriter].
+// Input of up to [ParallelBlockSize] bytes is compressed to the exact same output as [Gzip].
+func GzipParallel(data []byte, workers int) []byte {
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	pw, _ := NewParallelWriter(buf, gzip.DefaultCompression, workers)
+	_, _ = pw.Write(data)
+	_ = pw.Close()
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	return res
+}
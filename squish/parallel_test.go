@@ -0,0 +1,132 @@
+package squish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os/exec"
+	"strconv"
+	"testing"
+)
+
+func TestGzipParallel(t *testing.T) {
+	data := logLines(40000)
+	t.Logf("%d bytes over %d blocks", len(data), len(data)/ParallelBlockSize+1)
+
+	for _, workers := range []int{0, 1, 3, 16} {
+		packed := GzipParallel(data, workers)
+		out, err := Gunzip(packed)
+		if err != nil || !bytes.Equal(out, data) {
+			t.Fatalf("[FAIL] %d workers: round trip failed: %v", workers, err)
+		}
+		if len(packed) >= len(data)/4 {
+			t.Errorf("[FAIL] %d workers: poor compression, %d bytes", workers, len(packed))
+		}
+	}
+
+	if !bytes.Equal(GzipParallel([]byte(lip), 4), Gzip([]byte(lip))) {
+		t.Error("[FAIL] single block input should produce the same output as Gzip")
+	}
+	if !bytes.Equal(GzipParallel(nil, 4), Gzip(nil)) {
+		t.Error("[FAIL] empty input should produce the same output as Gzip")
+	}
+	if out, err := Gunzip(GzipParallel(data[:ParallelBlockSize], 2)); err != nil || !bytes.Equal(out, data[:ParallelBlockSize]) {
+		t.Errorf("[FAIL] exactly one block: round trip failed: %v", err)
+	}
+}
+
+func TestGzipParallelStockGunzip(t *testing.T) {
+	gunzip, err := exec.LookPath("gunzip")
+	if err != nil {
+		t.Skip("gunzip not found")
+	}
+	data := logLines(30000)
+	cmd := exec.Command(gunzip, "-c")
+	cmd.Stdin = bytes.NewReader(GzipParallel(data, 4))
+	out, err := cmd.Output()
+	if err != nil || !bytes.Equal(out, data) {
+		t.Errorf("[FAIL] stock gunzip could not read the output: %v", err)
+	}
+}
+
+type failWriter struct{ after int }
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	if f.after <= 0 {
+		return 0, io.ErrShortWrite
+	}
+	f.after--
+	return len(p), nil
+}
+
+func TestParallelWriter(t *testing.T) {
+	data := logLines(30000)
+	buf := new(bytes.Buffer)
+	pw, err := NewParallelWriter(buf, gzip.BestSpeed, 2)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	for p := data; len(p) > 0; {
+		n := min(len(p), 77777)
+		if _, err = pw.Write(p[:n]); err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		p = p[n:]
+	}
+	if err = pw.Flush(); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	flushed := buf.Len()
+	if out, err := Gunzip(buf.Bytes()); err != nil || !bytes.Equal(out, data) {
+		t.Fatalf("[FAIL] flushed output should be complete: %v", err)
+	}
+	_, _ = pw.Write([]byte(lip))
+	if err = pw.Close(); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if buf.Len() == flushed {
+		t.Error("[FAIL] Close should have written the data after Flush")
+	}
+	if out, err := Gunzip(buf.Bytes()); err != nil || string(out) != string(data)+lip {
+		t.Errorf("[FAIL] round trip failed: %v", err)
+	}
+	if _, err = pw.Write([]byte("yeet")); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+	if err = pw.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+
+	if _, err = NewParallelWriter(io.Discard, 42, 1); err == nil {
+		t.Error("[FAIL] NewParallelWriter should fail on an invalid level")
+	}
+	pw, _ = NewParallelWriter(&failWriter{after: 1}, gzip.BestSpeed, 1)
+	_, err = pw.Write(data)
+	if err == nil {
+		err = pw.Close()
+	}
+	if !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("[FAIL] wanted the underlying write error, got %v", err)
+	}
+}
+
+func BenchmarkGzipParallel(b *testing.B) {
+	data := logLines(400000)
+	b.Run("Gzip", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			Gzip(data)
+		}
+	})
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run("GzipParallel/"+strconv.Itoa(workers), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				GzipParallel(data, workers)
+			}
+		})
+	}
+}
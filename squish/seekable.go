@@ -0,0 +1,381 @@
+package squish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+const (
+	// DefaultChunkSize is the amount of uncompressed data per chunk used by [NewSeekableWriter] when given 0.
+	DefaultChunkSize = 64 << 10
+	// MaxChunkSize is the largest chunk size accepted by [NewSeekableWriter].
+	MaxChunkSize = 16 << 20
+)
+
+var (
+	// ErrNotSeekable is returned by [NewSeekableReader] and [NewSeekableReaderSize] when the input is not a seekable container.
+	ErrNotSeekable = errors.New("squish: not a seekable container")
+	// ErrUnknownSize is returned by [NewSeekableReader] when the size of its input can't be determined.
+	ErrUnknownSize = errors.New("squish: unable to determine input size")
+)
+
+// The container is a plain multi-member gzip stream, so any gzip reader (including [Gunzip])
+// can decompress it as a whole:
+//
+//	chunk member 0 .. chunk member n-1   independent gzip members of chunkSize bytes each (the last may be shorter)
+//	index member 0 .. index member m-1   empty gzip members whose "SI" extra field holds
+//	                                     the little endian uint32 compressed size of each chunk
+//	footer member                        an empty gzip member whose "SF" extra field holds the
+//	                                     version, chunk size, chunk count, total size and index offset
+//
+// The index and footer members are built by hand so that the footer has a fixed size.
+const (
+	seekableVersion = 1
+	// version, chunk size, chunk count, uncompressed size, offset of the first index member
+	seekableFooterData = 1 + 4 + 8 + 8 + 8
+	// gzip header, xlen and subfield header, then an empty final stored block, crc32 and isize
+	emptyMemberOverhead = 10 + 2 + 4 + 5 + 8
+	seekableFooterLen   = emptyMemberOverhead + seekableFooterData
+	// the extra field is at most 65535 bytes including the 4 byte subfield header
+	maxIndexEntries = (0xffff - 4) / 4
+)
+
+var (
+	indexSubfield  = [2]byte{'S', 'I'}
+	footerSubfield = [2]byte{'S', 'F'}
+)
+
+// emptyMember returns a gzip member with no content and a single extra subfield.
+func emptyMember(id [2]byte, data []byte) []byte {
+	const flagExtra = 1 << 2
+	b := make([]byte, 0, emptyMemberOverhead+len(data))
+	b = append(b, 0x1f, 0x8b, 8, flagExtra, 0, 0, 0, 0, 0, 0xff)
+	b = binary.LittleEndian.AppendUint16(b, uint16(4+len(data)))
+	b = append(b, id[0], id[1])
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(data)))
+	b = append(b, data...)
+	b = append(b, 1, 0, 0, 0xff, 0xff)
+	return append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+}
+
+// parseEmptyMember is the inverse of emptyMember. It returns the subfield data and the length of the member.
+func parseEmptyMember(b []byte, id [2]byte) ([]byte, int, error) {
+	if len(b) < emptyMemberOverhead {
+		return nil, 0, ErrNotSeekable
+	}
+	dataLen := int(binary.LittleEndian.Uint16(b[14:16]))
+	n := emptyMemberOverhead + dataLen
+	want := emptyMember(id, make([]byte, dataLen))
+	if len(b) < n || !bytes.Equal(b[:16], want[:16]) || !bytes.Equal(b[16+dataLen:n], want[16+dataLen:]) {
+		return nil, 0, ErrNotSeekable
+	}
+	return b[16 : 16+dataLen], n, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// SeekableWriter compresses data into a chunked, seekable gzip container for use with [SeekableReader].
+// Data is buffered until a full chunk is available; Close must be called to write the final chunk,
+// index and footer. Closing a SeekableWriter does not close the underlying [io.Writer].
+type SeekableWriter struct {
+	w     *countingWriter
+	pool  *sync.Pool
+	buf   []byte
+	sizes []uint32
+	total int64
+	err   error
+}
+
+// NewSeekableWriter returns a [SeekableWriter] that writes to w, compressing every chunkSize bytes of input
+// into an independent gzip member at the given level. A chunkSize of 0 means [DefaultChunkSize].
+// Smaller chunks make random access cheaper at the cost of compression ratio.
+func NewSeekableWriter(w io.Writer, level, chunkSize int) (*SeekableWriter, error) {
+	pool, ok := gzipPools[level]
+	if !ok {
+		return nil, fmt.Errorf("gzip: invalid compression level: %d", level)
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if chunkSize < 0 || chunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("squish: invalid chunk size: %d", chunkSize)
+	}
+	return &SeekableWriter{w: &countingWriter{w: w}, pool: pool, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+// Write buffers p, compressing and writing out every chunk as it fills up.
+func (sw *SeekableWriter) Write(p []byte) (int, error) {
+	if sw.err != nil {
+		return 0, sw.err
+	}
+	n := 0
+	for len(p) > 0 {
+		take := min(len(p), cap(sw.buf)-len(sw.buf))
+		sw.buf = append(sw.buf, p[:take]...)
+		p = p[take:]
+		n += take
+		if len(sw.buf) == cap(sw.buf) {
+			if sw.err = sw.writeChunk(); sw.err != nil {
+				return n, sw.err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (sw *SeekableWriter) writeChunk() error {
+	start := sw.w.n
+	gz := sw.pool.Get().(*gzip.Writer)
+	gz.Reset(sw.w)
+	_, err := gz.Write(sw.buf)
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	gz.Reset(nil)
+	sw.pool.Put(gz)
+	if err != nil {
+		return err
+	}
+	sw.sizes = append(sw.sizes, uint32(sw.w.n-start))
+	sw.total += int64(len(sw.buf))
+	sw.buf = sw.buf[:0]
+	return nil
+}
+
+// Close writes any buffered data as the final chunk, followed by the index and footer.
+// Calling Close more than once returns [ErrClosed].
+func (sw *SeekableWriter) Close() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	if len(sw.buf) > 0 {
+		if sw.err = sw.writeChunk(); sw.err != nil {
+			return sw.err
+		}
+	}
+	sw.err = ErrClosed
+
+	indexOffset := sw.w.n
+	for sizes := sw.sizes; len(sizes) > 0; {
+		n := min(len(sizes), maxIndexEntries)
+		data := make([]byte, 0, n*4)
+		for _, s := range sizes[:n] {
+			data = binary.LittleEndian.AppendUint32(data, s)
+		}
+		if _, err := sw.w.Write(emptyMember(indexSubfield, data)); err != nil {
+			return err
+		}
+		sizes = sizes[n:]
+	}
+
+	footer := make([]byte, 0, seekableFooterData)
+	footer = append(footer, seekableVersion)
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(cap(sw.buf)))
+	footer = binary.LittleEndian.AppendUint64(footer, uint64(len(sw.sizes)))
+	footer = binary.LittleEndian.AppendUint64(footer, uint64(sw.total))
+	footer = binary.LittleEndian.AppendUint64(footer, uint64(indexOffset))
+	_, err := sw.w.Write(emptyMember(footerSubfield, footer))
+	return err
+}
+
+// SeekableReader provides random access to the uncompressed contents of a container written by
+// [SeekableWriter], only inflating the chunks that are actually read.
+// ReadAt is safe for concurrent use; Read and Seek share a single offset and are not.
+type SeekableReader struct {
+	r         io.ReaderAt
+	chunkSize int64
+	size      int64
+	// offsets holds the compressed offset of every chunk, plus the offset of the index at the end.
+	offsets []int64
+	pos     int64
+
+	mu       sync.Mutex
+	cacheIdx int64
+	cache    []byte
+}
+
+// NewSeekableReader returns a [SeekableReader] over a container written by [SeekableWriter].
+// The size of r is determined through a Size() or Stat() method, as provided by [bytes.Reader],
+// [io.SectionReader] and [os.File] among others; otherwise [ErrUnknownSize] is returned,
+// and [NewSeekableReaderSize] should be used instead.
+func NewSeekableReader(r io.ReaderAt) (*SeekableReader, error) {
+	var size int64
+	switch s := r.(type) {
+	case interface{ Size() int64 }:
+		size = s.Size()
+	case interface{ Stat() (fs.FileInfo, error) }:
+		fi, err := s.Stat()
+		if err != nil {
+			return nil, err
+		}
+		size = fi.Size()
+	default:
+		return nil, ErrUnknownSize
+	}
+	return NewSeekableReaderSize(r, size)
+}
+
+// NewSeekableReaderSize is like [NewSeekableReader], for readers that can't report their own size,
+// such as HTTP range readers or blobs in object storage. size is the total size of the container in r.
+func NewSeekableReaderSize(r io.ReaderAt, size int64) (*SeekableReader, error) {
+	if size < seekableFooterLen {
+		return nil, ErrNotSeekable
+	}
+
+	raw := make([]byte, seekableFooterLen)
+	if _, err := r.ReadAt(raw, size-seekableFooterLen); err != nil {
+		return nil, err
+	}
+	footer, _, err := parseEmptyMember(raw, footerSubfield)
+	if err != nil || len(footer) != seekableFooterData {
+		return nil, ErrNotSeekable
+	}
+	if footer[0] != seekableVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrNotSeekable, footer[0])
+	}
+	sr := &SeekableReader{
+		r:         r,
+		chunkSize: int64(binary.LittleEndian.Uint32(footer[1:])),
+		size:      int64(binary.LittleEndian.Uint64(footer[13:])),
+		cacheIdx:  -1,
+	}
+	chunks := binary.LittleEndian.Uint64(footer[5:])
+	indexOffset := int64(binary.LittleEndian.Uint64(footer[21:]))
+	indexLen := size - seekableFooterLen - indexOffset
+	if sr.chunkSize <= 0 || sr.chunkSize > MaxChunkSize || indexOffset < 0 || indexLen < 0 || chunks > uint64(indexLen)/4 {
+		return nil, ErrNotSeekable
+	}
+	// every chunk but the last is full, and the last one isn't empty.
+	if n := int64(chunks); sr.size > n*sr.chunkSize || (n > 0 && sr.size <= (n-1)*sr.chunkSize) {
+		return nil, ErrNotSeekable
+	}
+
+	index := make([]byte, indexLen)
+	if _, err = r.ReadAt(index, indexOffset); err != nil {
+		return nil, err
+	}
+	sr.offsets = make([]int64, 1, chunks+1)
+	for len(index) > 0 {
+		data, n, err := parseEmptyMember(index, indexSubfield)
+		if err != nil || len(data)%4 != 0 {
+			return nil, ErrNotSeekable
+		}
+		for i := 0; i < len(data); i += 4 {
+			sr.offsets = append(sr.offsets, sr.offsets[len(sr.offsets)-1]+int64(binary.LittleEndian.Uint32(data[i:])))
+		}
+		index = index[n:]
+	}
+	if uint64(len(sr.offsets)) != chunks+1 || sr.offsets[chunks] != indexOffset {
+		return nil, ErrNotSeekable
+	}
+	return sr, nil
+}
+
+// Size returns the uncompressed size of the container's contents.
+func (sr *SeekableReader) Size() int64 {
+	return sr.size
+}
+
+// ChunkSize returns the amount of uncompressed data in every chunk but the last.
+func (sr *SeekableReader) ChunkSize() int {
+	return int(sr.chunkSize)
+}
+
+// loadChunk inflates chunk idx into the cache, sr.mu must be held.
+func (sr *SeekableReader) loadChunk(idx int64) error {
+	if sr.cacheIdx == idx {
+		return nil
+	}
+	want := min(sr.chunkSize, sr.size-idx*sr.chunkSize)
+	if cap(sr.cache) < int(sr.chunkSize) {
+		sr.cache = make([]byte, sr.chunkSize)
+	}
+	sr.cache = sr.cache[:want]
+	sr.cacheIdx = -1
+
+	section := io.NewSectionReader(sr.r, sr.offsets[idx], sr.offsets[idx+1]-sr.offsets[idx])
+	gz := gzipReaderPool.Get().(*gzip.Reader)
+	defer gzipReaderPool.Put(gz)
+	if err := gz.Reset(section); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(gz, sr.cache); err != nil {
+		return fmt.Errorf("squish: chunk %d: %w", idx, err)
+	}
+	// make sure the chunk holds exactly what the index says, and passes its checksum.
+	if n, err := gz.Read(make([]byte, 1)); n != 0 || !errors.Is(err, io.EOF) {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = ErrNotSeekable
+		}
+		return fmt.Errorf("squish: chunk %d: %w", idx, err)
+	}
+	sr.cacheIdx = idx
+	return nil
+}
+
+// ReadAt reads len(p) bytes of uncompressed data starting at off, see [io.ReaderAt].
+func (sr *SeekableReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("squish: negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		if off >= sr.size {
+			return n, io.EOF
+		}
+		idx := off / sr.chunkSize
+		sr.mu.Lock()
+		if err := sr.loadChunk(idx); err != nil {
+			sr.mu.Unlock()
+			return n, err
+		}
+		c := copy(p[n:], sr.cache[off-idx*sr.chunkSize:])
+		sr.mu.Unlock()
+		n += c
+		off += int64(c)
+	}
+	return n, nil
+}
+
+// Read reads uncompressed data from the current offset.
+func (sr *SeekableReader) Read(p []byte) (int, error) {
+	if sr.pos >= sr.size {
+		return 0, io.EOF
+	}
+	n, err := sr.ReadAt(p[:min(int64(len(p)), sr.size-sr.pos)], sr.pos)
+	sr.pos += int64(n)
+	return n, err
+}
+
+// Seek sets the offset for the next Read into the uncompressed data, see [io.Seeker].
+func (sr *SeekableReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += sr.pos
+	case io.SeekEnd:
+		offset += sr.size
+	default:
+		return 0, errors.New("squish: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("squish: negative position")
+	}
+	sr.pos = offset
+	return offset, nil
+}
@@ -0,0 +1,218 @@
+package squish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func logLines(n int) []byte {
+	buf := new(bytes.Buffer)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(buf, "2023-04-%02d 13:37:%02d sshd[%d]: Failed password for invalid user user%d from 10.0.%d.%d\n",
+			i%30+1, i%60, 1000+i%77, i%113, i%256, i%199)
+	}
+	return buf.Bytes()
+}
+
+func seekable(t testing.TB, data []byte, chunkSize int) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	sw, err := NewSeekableWriter(buf, gzip.BestSpeed, chunkSize)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	// uneven writes, so that chunks are filled across write boundaries
+	for p := data; len(p) > 0; {
+		n := min(len(p), 1000)
+		if _, err = sw.Write(p[:n]); err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		p = p[n:]
+	}
+	if err = sw.Close(); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	return buf.Bytes()
+}
+
+func TestSeekable(t *testing.T) {
+	data := logLines(10000)
+	for _, chunkSize := range []int{0, 4096, len(data) / 4, len(data) + 1} {
+		packed := seekable(t, data, chunkSize)
+		t.Logf("chunk size %d: %d bytes packed to %d", chunkSize, len(data), len(packed))
+
+		plain, err := Gunzip(packed)
+		if err != nil || !bytes.Equal(plain, data) {
+			t.Fatalf("[FAIL] container should be readable as plain gzip: %v", err)
+		}
+
+		sr, err := NewSeekableReader(bytes.NewReader(packed))
+		if err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		if sr.Size() != int64(len(data)) {
+			t.Errorf("[FAIL] wanted size %d, got %d", len(data), sr.Size())
+		}
+		all, err := io.ReadAll(sr)
+		if err != nil || !bytes.Equal(all, data) {
+			t.Fatalf("[FAIL] sequential read mismatch: %v", err)
+		}
+
+		for _, off := range []int64{0, 1, 4095, 4096, 4097, 12345, int64(len(data)) / 2, int64(len(data)) - 10000} {
+			got := make([]byte, 10000)
+			if n, err := sr.ReadAt(got, off); err != nil || n != len(got) || !bytes.Equal(got, data[off:off+10000]) {
+				t.Errorf("[FAIL] ReadAt(%d) mismatch: %d bytes, %v", off, n, err)
+			}
+		}
+		tail := make([]byte, 100)
+		if n, err := sr.ReadAt(tail, int64(len(data))-10); n != 10 || !errors.Is(err, io.EOF) {
+			t.Errorf("[FAIL] ReadAt past the end: wanted 10 bytes and EOF, got %d and %v", n, err)
+		}
+
+		if pos, err := sr.Seek(-20, io.SeekEnd); err != nil || pos != int64(len(data))-20 {
+			t.Errorf("[FAIL] Seek from end: %d, %v", pos, err)
+		}
+		if _, err = sr.Seek(-5, io.SeekCurrent); err != nil {
+			t.Fatalf("[FAIL] %s", err.Error())
+		}
+		rest, err := io.ReadAll(sr)
+		if err != nil || !bytes.Equal(rest, data[len(data)-25:]) {
+			t.Errorf("[FAIL] read after seek mismatch: %q, %v", rest, err)
+		}
+		if _, err = sr.Seek(-1, io.SeekStart); err == nil {
+			t.Error("[FAIL] seeking to a negative position should fail")
+		}
+	}
+}
+
+func TestSeekableEdges(t *testing.T) {
+	sr, err := NewSeekableReader(bytes.NewReader(seekable(t, nil, 0)))
+	if err != nil {
+		t.Fatalf("[FAIL] empty container: %s", err.Error())
+	}
+	if n, err := sr.Read(make([]byte, 10)); n != 0 || !errors.Is(err, io.EOF) {
+		t.Errorf("[FAIL] empty container: wanted EOF, got %d, %v", n, err)
+	}
+
+	// exact multiple of the chunk size, and more chunks than fit in one index member
+	data := logLines(200)[:20000]
+	packed := seekable(t, data, 1)
+	sr, err = NewSeekableReader(bytes.NewReader(packed))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	got := make([]byte, 300)
+	if _, err = sr.ReadAt(got, 19000); err != nil || !bytes.Equal(got, data[19000:19300]) {
+		t.Errorf("[FAIL] ReadAt across one byte chunks mismatch: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "archive.gz")
+	if err = os.WriteFile(path, seekable(t, data, 1000), 0o644); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	defer f.Close()
+	if sr, err = NewSeekableReader(f); err != nil {
+		t.Fatalf("[FAIL] os.File: %s", err.Error())
+	}
+
+	// concurrent ReadAt calls share the chunk cache
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(off int64) {
+			defer wg.Done()
+			got := make([]byte, 2500)
+			if _, err := sr.ReadAt(got, off); err != nil || !bytes.Equal(got, data[off:off+2500]) {
+				t.Errorf("[FAIL] concurrent ReadAt(%d) mismatch: %v", off, err)
+			}
+		}(int64(i) * 2000)
+	}
+	wg.Wait()
+}
+
+func TestSeekableErrors(t *testing.T) {
+	if _, err := NewSeekableWriter(io.Discard, 42, 0); err == nil {
+		t.Error("[FAIL] NewSeekableWriter should fail on an invalid level")
+	}
+	if _, err := NewSeekableWriter(io.Discard, gzip.BestSpeed, MaxChunkSize+1); err == nil {
+		t.Error("[FAIL] NewSeekableWriter should fail on an invalid chunk size")
+	}
+	sw, _ := NewSeekableWriter(io.Discard, gzip.BestSpeed, 0)
+	_ = sw.Close()
+	if _, err := sw.Write([]byte("yeet")); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+	if err := sw.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+
+	data := logLines(1000)
+	packed := seekable(t, data, 4096)
+	for name, bad := range map[string][]byte{
+		"plain gzip": Gzip(data),
+		"junk":       []byte("junk"),
+		"truncated":  packed[:len(packed)-1],
+		"extended":   append(append([]byte{}, packed...), 0),
+	} {
+		if _, err := NewSeekableReader(bytes.NewReader(bad)); !errors.Is(err, ErrNotSeekable) {
+			t.Errorf("[FAIL] %s: wanted ErrNotSeekable, got %v", name, err)
+		}
+	}
+	if _, err := NewSeekableReader(struct{ io.ReaderAt }{bytes.NewReader(packed)}); !errors.Is(err, ErrUnknownSize) {
+		t.Errorf("[FAIL] wanted ErrUnknownSize, got %v", err)
+	}
+	sized, err := NewSeekableReaderSize(struct{ io.ReaderAt }{bytes.NewReader(packed)}, int64(len(packed)))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if all, err := io.ReadAll(sized); err != nil || !bytes.Equal(all, data) {
+		t.Errorf("[FAIL] NewSeekableReaderSize read mismatch: %v", err)
+	}
+	for _, size := range []int64{0, int64(len(packed)) - 1, int64(len(packed)) + 1} {
+		if _, err = NewSeekableReaderSize(bytes.NewReader(packed), size); err == nil {
+			t.Errorf("[FAIL] NewSeekableReaderSize with the wrong size %d should fail", size)
+		}
+	}
+
+	corrupt := append([]byte{}, packed...)
+	corrupt[5000] ^= 0xff
+	sr, err := NewSeekableReader(bytes.NewReader(corrupt))
+	if err != nil {
+		t.Fatalf("[FAIL] corrupting a chunk should not break the index: %s", err.Error())
+	}
+	if _, err = sr.ReadAt(make([]byte, 100), 0); err != nil {
+		t.Errorf("[FAIL] chunks before the corruption should still be readable: %s", err.Error())
+	}
+	if _, err = io.ReadAll(sr); err == nil {
+		t.Error("[FAIL] reading a corrupt chunk should fail")
+	}
+}
+
+func BenchmarkSeekableReadAt(b *testing.B) {
+	data := logLines(100000)
+	sr, err := NewSeekableReader(bytes.NewReader(seekable(b, data, 0)))
+	if err != nil {
+		b.Fatalf("[FAIL] %s", err.Error())
+	}
+	buf := make([]byte, 4096)
+	b.SetBytes(int64(len(buf)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		off := int64(n*7919*4096) % (sr.Size() - int64(len(buf)))
+		if _, err = sr.ReadAt(buf, off); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
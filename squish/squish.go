@@ -16,9 +16,26 @@ var (
 		},
 	}
 
-	gzipPool = &sync.Pool{
+	// gzipPools holds a pool of *gzip.Writer for every valid compression level.
+	gzipPools = func() map[int]*sync.Pool {
+		pools := make(map[int]*sync.Pool, gzip.BestCompression-gzip.HuffmanOnly+1)
+		for level := gzip.HuffmanOnly; level <= gzip.BestCompression; level++ {
+			lvl := level
+			pools[lvl] = &sync.Pool{
+				New: func() interface{} {
+					gz, _ := gzip.NewWriterLevel(nil, lvl)
+					return gz
+				},
+			}
+		}
+		return pools
+	}()
+
+	gzipPool = gzipPools[gzip.DefaultCompression]
+
+	gzipReaderPool = &sync.Pool{
 		New: func() interface{} {
-			return gzip.NewWriter(nil)
+			return new(gzip.Reader)
 		},
 	}
 )
@@ -28,35 +45,34 @@ func Gzip(data []byte) []byte {
 	buf := bufPool.Get().(*bytes.Buffer)
 	gz := gzipPool.Get().(*gzip.Writer)
 	buf.Reset()
-	r, w := io.Pipe()
-	gz.Reset(w)
-	go func() {
-		_, _ = gz.Write(data)
-		_ = gz.Close()
-		_ = w.Close()
-	}()
-	n, _ := buf.ReadFrom(r)
-	buf.Truncate(int(n))
-	_ = r.Close()
-	res, _ := io.ReadAll(buf)
+	gz.Reset(buf)
+	_, _ = gz.Write(data)
+	_ = gz.Close()
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
+	gz.Reset(nil)
 	bufPool.Put(buf)
 	gzipPool.Put(gz)
 	return res
 }
 
 // Gunzip decompresses a gzip compressed slice of bytes.
-func Gunzip(data []byte) (out []byte, err error) {
-	gz, err := gzip.NewReader(bytes.NewReader(data))
-	if err != nil {
+// If [Limits] are given, decompression stops with [ErrLimitExceeded] as soon as one is crossed.
+func Gunzip(data []byte, limits ...Limits) (out []byte, err error) {
+	gz := gzipReaderPool.Get().(*gzip.Reader)
+	defer gzipReaderPool.Put(gz)
+	in := &countingReader{r: bytes.NewReader(data)}
+	if err = gz.Reset(in); err != nil {
 		return nil, err
 	}
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
-	var n int64
-	n, _ = buf.ReadFrom(gz)
-	err = gz.Close()
-	buf.Truncate(int(n))
-	res, _ := io.ReadAll(buf)
+	_, err = buf.ReadFrom(limitReader(gz, in, firstLimits(limits)))
+	if closeErr := gz.Close(); err == nil {
+		err = closeErr
+	}
+	res := make([]byte, buf.Len())
+	copy(res, buf.Bytes())
 	bufPool.Put(buf)
 	return res, err
 }
@@ -76,6 +92,7 @@ func B64e(in []byte) (out string) {
 
 // B64d decodes the given string into the original slice of bytes.
 // Do note that this is for non critical tasks, it has no error handling for purposes of clean code.
+// Use [B64dErr] to tell corrupt input apart from empty input.
 func B64d(str string) (data []byte) {
 	if len(str) == 0 {
 		return nil
@@ -85,12 +102,13 @@ func B64d(str string) (data []byte) {
 }
 
 // UnpackStr UNsafely unpacks (usually banners) that have been base64'd and then gzip'd.
-func UnpackStr(encoded string) (string, error) {
+// Optional [Limits] are passed on to [Gunzip].
+func UnpackStr(encoded string, limits ...Limits) (string, error) {
 	one := B64d(encoded)
 	if len(one) == 0 {
 		return "", errors.New("0 length base64 decoding result")
 	}
-	dcytes, err := Gunzip(one)
+	dcytes, err := Gunzip(one, limits...)
 	if err != nil && !errors.Is(err, io.EOF) {
 		return "", err
 	}
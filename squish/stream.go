@@ -0,0 +1,111 @@
+package squish
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrClosed is returned when using a [Writer] or [Reader] after it has been closed.
+var ErrClosed = errors.New("squish: use of closed stream")
+
+// Writer is a pooled streaming gzip compressor. Data written to it is compressed and written
+// to the underlying [io.Writer] as it goes, without buffering the whole input.
+//
+// Close must be called to flush the gzip footer; it also returns the compressor to its pool.
+// Closing a Writer does not close the underlying [io.Writer].
+type Writer struct {
+	gz   *gzip.Writer
+	pool *sync.Pool
+}
+
+// NewWriter returns a pooled gzip [Writer] that compresses to w at the given level.
+// Level may be any of the levels accepted by [gzip.NewWriterLevel].
+func NewWriter(w io.Writer, level int) (*Writer, error) {
+	pool, ok := gzipPools[level]
+	if !ok {
+		return nil, fmt.Errorf("gzip: invalid compression level: %d", level)
+	}
+	gz := pool.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return &Writer{gz: gz, pool: pool}, nil
+}
+
+// Write compresses p to the underlying writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.gz == nil {
+		return 0, ErrClosed
+	}
+	return w.gz.Write(p)
+}
+
+// Flush flushes any pending compressed data to the underlying writer, see [gzip.Writer.Flush].
+func (w *Writer) Flush() error {
+	if w.gz == nil {
+		return ErrClosed
+	}
+	return w.gz.Flush()
+}
+
+// Close writes the gzip footer and returns the compressor to its pool.
+// Calling Close more than once returns [ErrClosed].
+func (w *Writer) Close() error {
+	if w.gz == nil {
+		return ErrClosed
+	}
+	err := w.gz.Close()
+	w.gz.Reset(nil)
+	w.pool.Put(w.gz)
+	w.gz = nil
+	return err
+}
+
+// Reader is a pooled streaming gzip decompressor.
+// Close returns the decompressor to its pool; it does not close the underlying [io.Reader].
+type Reader struct {
+	gz  *gzip.Reader
+	src io.Reader
+}
+
+// NewReader returns a pooled gzip [Reader] that decompresses from r.
+// The gzip header is read immediately, so an error is returned if r does not contain gzip data.
+// If [Limits] are given, Read returns [ErrLimitExceeded] as soon as one is crossed.
+func NewReader(r io.Reader, limits ...Limits) (*Reader, error) {
+	gz := gzipReaderPool.Get().(*gzip.Reader)
+	in := &countingReader{r: r}
+	if err := gz.Reset(in); err != nil {
+		gzipReaderPool.Put(gz)
+		return nil, err
+	}
+	return &Reader{gz: gz, src: limitReader(gz, in, firstLimits(limits))}, nil
+}
+
+// Read decompresses into p.
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.gz == nil {
+		return 0, ErrClosed
+	}
+	return r.src.Read(p)
+}
+
+// Header returns the gzip header of the current member.
+func (r *Reader) Header() gzip.Header {
+	if r.gz == nil {
+		return gzip.Header{}
+	}
+	return r.gz.Header
+}
+
+// Close returns the decompressor to its pool.
+// Calling Close more than once returns [ErrClosed].
+func (r *Reader) Close() error {
+	if r.gz == nil {
+		return ErrClosed
+	}
+	err := r.gz.Close()
+	gzipReaderPool.Put(r.gz)
+	r.gz, r.src = nil, nil
+	return err
+}
@@ -0,0 +1,141 @@
+package squish
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	for level := gzip.HuffmanOnly; level <= gzip.BestCompression; level++ {
+		t.Run("level"+strconv.Itoa(level), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			w, err := NewWriter(buf, level)
+			if err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			// feed it in small, uneven pieces
+			for _, line := range strings.SplitAfter(lip, " ") {
+				if _, err = w.Write([]byte(line)); err != nil {
+					t.Fatalf("[FAIL] %s", err.Error())
+				}
+			}
+			if err = w.Close(); err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			if level != gzip.NoCompression && buf.Len() >= len(lip) {
+				t.Errorf("[FAIL] level %d did not compress: %d >= %d", level, buf.Len(), len(lip))
+			}
+
+			// compatible with the one-shot helper
+			out, err := Gunzip(buf.Bytes())
+			if err != nil || string(out) != lip {
+				t.Fatalf("[FAIL] Gunzip could not read NewWriter output: %v", err)
+			}
+
+			r, err := NewReader(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("[FAIL] %s", err.Error())
+			}
+			out, err = io.ReadAll(r)
+			if err != nil || string(out) != lip {
+				t.Fatalf("[FAIL] NewReader round trip failed: %v", err)
+			}
+			if err = r.Close(); err != nil {
+				t.Errorf("[FAIL] %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestStreamDefaultMatchesGzip(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, gzip.DefaultCompression)
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	_, _ = w.Write([]byte(lip))
+	_ = w.Close()
+	if !bytes.Equal(buf.Bytes(), Gzip([]byte(lip))) {
+		t.Error("[FAIL] NewWriter at the default level should produce the same output as Gzip")
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	if _, err := NewWriter(io.Discard, 42); err == nil {
+		t.Error("[FAIL] NewWriter should fail on an invalid level")
+	}
+	if _, err := NewReader(strings.NewReader("junk")); err == nil {
+		t.Error("[FAIL] NewReader should fail on junk input")
+	}
+
+	w, _ := NewWriter(io.Discard, gzip.BestSpeed)
+	_ = w.Close()
+	if _, err := w.Write([]byte("yeet")); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+	if err := w.Flush(); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+	if err := w.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(Gzip([]byte(lip))))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	if r.Header().OS != 255 {
+		t.Errorf("[FAIL] wanted unknown OS (255) in header, got %d", r.Header().OS)
+	}
+	_ = r.Close()
+	if _, err = r.Read(make([]byte, 1)); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+	if err = r.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("[FAIL] wanted ErrClosed, got %v", err)
+	}
+}
+
+func TestStreamFlush(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, gzip.DefaultCompression)
+	_, _ = w.Write([]byte(lip[:100]))
+	if err := w.Flush(); err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("[FAIL] %s", err.Error())
+	}
+	got := make([]byte, 100)
+	if _, err = io.ReadFull(r, got); err != nil || string(got) != lip[:100] {
+		t.Errorf("[FAIL] flushed data was not readable: %v", err)
+	}
+	_ = r.Close()
+	_ = w.Close()
+}
+
+func BenchmarkGzip(b *testing.B) {
+	dat := []byte(strings.Repeat(lip, 20))
+	b.Run("Gzip", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(dat)))
+		for i := 0; i < b.N; i++ {
+			Gzip(dat)
+		}
+	})
+	b.Run("NewWriter", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(dat)))
+		for i := 0; i < b.N; i++ {
+			w, _ := NewWriter(io.Discard, gzip.DefaultCompression)
+			_, _ = w.Write(dat)
+			_ = w.Close()
+		}
+	})
+}